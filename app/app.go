@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"log/slog"
+	"os"
 	"sort"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -55,6 +56,12 @@ func NewApp(startup func(*App)) *App {
 				app.Gesturer().DebugPrint()
 			},
 		),
+		reaction.NewKeyDownReaction(
+			reaction.SpecificKeyDown(ebiten.KeyG),
+			func(event *reaction.KeyDownEvent) {
+				app.writeLayoutGraph()
+			},
+		),
 	)
 	app.Reactions().Register(app.Gesturer(), app.Z())
 
@@ -69,6 +76,10 @@ type App struct {
 	doodad.Default
 
 	WaitForInitialDimensions chan struct{}
+
+	// The size ebiten last gave us, which the layout may differ from if it
+	// has limits.
+	outsideWidth, outsideHeight int
 }
 
 func (g *App) Start() {
@@ -167,6 +178,14 @@ func (g *App) Update() error {
 
 	g.Gesturer().Update()
 
+	for _, d := range g.Children().FlattenedDoodads() {
+		if updater, ok := d.(doodad.Updater); ok {
+			if err := updater.Update(); err != nil {
+				slog.Error("Error updating doodad", "doodad", d.DebugName(), "error", err)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -224,12 +243,40 @@ func (g *App) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight
 	// 	g.WaitForInitialDimensions = nil
 	// }
 
-	if g.Default.Layout().Width() != outsideWidth || g.Default.Layout().Height() != outsideHeight {
-		g.Default.Layout().SetDimensions(outsideWidth, outsideHeight)
-		g.Default.Layout().Recalculate()
+	if g.outsideWidth != outsideWidth || g.outsideHeight != outsideHeight {
+		g.outsideWidth, g.outsideHeight = outsideWidth, outsideHeight
 
-		doodad.ReSetup(g.Current())
+		box.Batch(func() {
+			g.Default.Layout().SetDimensions(outsideWidth, outsideHeight)
+			g.Default.Layout().Recalculate()
+
+			doodad.ReSetup(g.Current())
+		})
 	}
 
 	return outsideWidth, outsideHeight
 }
+
+// writeLayoutGraph saves the current page's layout graph to the working
+// directory, as layout.dot and layout.json.
+func (g *App) writeLayoutGraph() {
+	if g.Current() == nil {
+		return
+	}
+
+	graph := doodad.NewGraph(g.Current())
+
+	data, err := graph.JSON()
+	if err != nil {
+		slog.Error("Failed to encode layout graph", "error", err)
+		return
+	}
+
+	for name, content := range map[string][]byte{"layout.dot": []byte(graph.DOT()), "layout.json": data} {
+		if err := os.WriteFile(name, content, 0o644); err != nil {
+			slog.Error("Failed to write layout graph", "file", name, "error", err)
+			continue
+		}
+		slog.Info("Wrote layout graph", "file", name)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/jhuggett/thingamabob/doodad"
 	"github.com/jhuggett/thingamabob/label"
+	"github.com/jhuggett/thingamabob/position/box"
 	"github.com/jhuggett/thingamabob/reaction"
 )
 
@@ -114,7 +115,15 @@ func (w *Button) Setup() {
 	// 	b.CopyDimensionsOf(boundingBox)
 	// })
 
-	w.ShrinkToFitContents()
+	// The button is as big as its label wants to be, unless a parent sizes it
+	// after this step, and the label always covers the whole button.
+	w.Box.NamedComputed("button.size", func(b *box.Box) {
+		size := doodad.Measure(buttonLabel, doodad.UnboundedConstraints())
+		b.SetDimensions(size.Width, size.Height)
+	})
+	buttonLabel.Layout().NamedComputed("button.label", func(b *box.Box) {
+		b.CopyDimensionsOf(w.Box)
+	})
 
 	w.Reactions().Add(
 		reaction.NewMouseUpReaction(
@@ -179,6 +188,24 @@ func (w *Button) Setup() {
 	)
 }
 
+// Buttons are as big as the label for their current state.
+func (w *Button) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	if w.Children() != nil && len(w.Children().Doodads) > 0 {
+		return doodad.Measure(w.Children().Doodads[0], constraints)
+	}
+	return doodad.Measure(w.labelForState[w.buttonState](), constraints)
+}
+
+func (w *Button) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(w, rect)
+	if w.Children() == nil {
+		return
+	}
+	for _, child := range w.Children().Doodads {
+		doodad.Arrange(child, rect)
+	}
+}
+
 func (w *Button) SetMessage(message string) {
 	w.message = message
 	doodad.ReSetup(w)
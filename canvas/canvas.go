@@ -22,6 +22,12 @@ func (i *Canvas) Setup() {
 }
 
 func (i *Canvas) Draw(screen *ebiten.Image) {
+	screen = doodad.Clipped(i, screen)
+	if screen == nil {
+		return
+	}
+
+	i.DrawShadows(screen)
 	if i.Render != nil {
 		i.Render(screen)
 	}
@@ -7,6 +7,28 @@ type Flow int
 const (
 	TopToBottom Flow = iota
 	LeftToRight
+	RightToLeft
+	BottomToTop
+)
+
+func (f Flow) IsHorizontal() bool {
+	return f == LeftToRight || f == RightToLeft
+}
+
+// IsReversed is true for flows that run from the right or the bottom.
+func (f Flow) IsReversed() bool {
+	return f == RightToLeft || f == BottomToTop
+}
+
+// Direction is the reading direction of a doodad's content. Horizontal flows
+// and alignments are mirrored when it is right to left.
+type Direction int
+
+const (
+	// Use the parent's direction, left to right at the root.
+	DirectionInherit Direction = iota
+	DirectionLeftToRight
+	DirectionRightToLeft
 )
 
 type Padding struct {
@@ -52,6 +74,8 @@ const (
 	VerticalAlignmentTop VerticalAlignment = iota
 	VerticalAlignmentCenter
 	VerticalAlignmentBottom
+	// Children are as tall as the space they are aligned in.
+	VerticalAlignmentStretch
 )
 
 type HorizontalAlignment int
@@ -60,4 +84,24 @@ const (
 	HorizontalAlignmentLeft HorizontalAlignment = iota
 	HorizontalAlignmentCenter
 	HorizontalAlignmentRight
+	// Children are as wide as the space they are aligned in.
+	HorizontalAlignmentStretch
+)
+
+// Justification is how children are spread along the flow when there is more
+// room than they need.
+type Justification int
+
+const (
+	JustificationStart Justification = iota
+	JustificationCenter
+	JustificationEnd
+	// The first and last children touch the ends, the rest of the room goes
+	// between children.
+	JustificationSpaceBetween
+	// Each child gets the same room on either side, so the ends get half as
+	// much as the gaps between children.
+	JustificationSpaceAround
+	// The ends and the gaps between children all get the same room.
+	JustificationSpaceEvenly
 )
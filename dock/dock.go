@@ -0,0 +1,121 @@
+package dock
+
+import (
+	"log/slog"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+type LayoutRule int
+
+const (
+	FitContents LayoutRule = iota
+	Fill
+)
+
+type Config struct {
+	Padding config.Padding
+	// The space left between a docked child and whatever comes after it.
+	Spacing int
+
+	LayoutRule LayoutRule
+}
+
+// Side is the edge of the dock a child is docked to.
+type Side int
+
+const (
+	// Takes whatever is left once every child docked to an edge has had its
+	// share. Only the last Center child that isn't collapsed is given that
+	// space. Any Center children before it get none.
+	Center Side = iota
+	Top
+	Bottom
+	Left
+	Right
+)
+
+func New(config Config) *Dock {
+	return &Dock{
+		Config: config,
+	}
+}
+
+// Dock gives each child an edge of whatever space the children before it have
+// left. Children docked to the top or bottom are as tall as they want and as
+// wide as the space left, children docked to the left or right the other way
+// around. The Center child, the default, is stretched over what remains after
+// all of that.
+type Dock struct {
+	Config Config
+
+	doodad.Default
+
+	sides map[doodad.Doodad]Side
+
+	// Child sizes from the last measure, and where they were placed.
+	measured   []doodad.Rectangle
+	slots      []box.Frame
+	slotsFrame box.Frame
+}
+
+// Place docks child to side. Call it before Setup.
+func (d *Dock) Place(child doodad.Doodad, side Side) {
+	if d.sides == nil {
+		d.sides = map[doodad.Doodad]Side{}
+	}
+	d.sides[child] = side
+}
+
+// Names of the calculation steps a dock owns.
+const (
+	positionStep  = "dock.position"
+	sizeChildStep = "dock.size-child"
+	sizeStep      = "dock.size"
+)
+
+func (d *Dock) Setup() {
+	centers := 0
+	for _, child := range d.Children().Doodads {
+		if d.side(child) == Center {
+			centers++
+		}
+	}
+	if centers > 1 {
+		slog.Warn("Dock has more than one Center child; only the last one is given the remaining space", "centers", centers)
+	}
+
+	for i, child := range d.Children().Doodads {
+		child.Layout().NamedComputed(positionStep, func(b *box.Box) {
+			slot := d.slot(i)
+			b.SetFrameOrigin(slot.X, slot.Y)
+		})
+	}
+
+	d.Children().Setup()
+
+	// Added after the children's own steps so the dock has the final say on
+	// their size.
+	for i, child := range d.Children().Doodads {
+		child.Layout().NamedComputed(sizeChildStep, func(b *box.Box) {
+			b.SetFrame(d.slot(i))
+		})
+	}
+
+	d.Box.NamedComputed(sizeStep, func(b *box.Box) {
+		d.measured = d.measureChildren()
+		d.slots = nil
+
+		switch d.Config.LayoutRule {
+		case FitContents:
+			content := d.contentSize(d.measured)
+			b.SetWidth(content.Width + d.Config.Padding.Left + d.Config.Padding.Right)
+			b.SetHeight(content.Height + d.Config.Padding.Top + d.Config.Padding.Bottom)
+
+		case Fill:
+			// Do nothing, we fill the available space
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package dock
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+func (d *Dock) measureChildren() []doodad.Rectangle {
+	sizes := make([]doodad.Rectangle, len(d.Children().Doodads))
+	for i, child := range d.Children().Doodads {
+		sizes[i] = doodad.MeasureWithMargin(child, doodad.UnboundedConstraints())
+	}
+	return sizes
+}
+
+// side is the side child is docked to, with left and right swapped for right
+// to left content.
+func (d *Dock) side(child doodad.Doodad) Side {
+	side := d.sides[child]
+	if d.Direction() != config.DirectionRightToLeft {
+		return side
+	}
+
+	switch side {
+	case Left:
+		return Right
+	case Right:
+		return Left
+	default:
+		return side
+	}
+}
+
+// center is the index of the Center child given whatever is left, the last
+// one that isn't collapsed, or -1 if there isn't one.
+func (d *Dock) center() int {
+	children := d.Children().Doodads
+	for i := len(children) - 1; i >= 0; i-- {
+		if d.side(children[i]) == Center && !children[i].IsCollapsed() {
+			return i
+		}
+	}
+	return -1
+}
+
+// contentSize is the smallest size that fits every child at the size it
+// wants, without the dock's padding. It is worked out from the center
+// outwards.
+func (d *Dock) contentSize(sizes []doodad.Rectangle) doodad.Rectangle {
+	children := d.Children().Doodads
+
+	content := doodad.Rectangle{}
+	if center := d.center(); center != -1 {
+		content = sizes[center]
+	}
+
+	for i := len(children) - 1; i >= 0; i-- {
+		if children[i].IsCollapsed() {
+			continue
+		}
+
+		size := sizes[i]
+		switch d.side(children[i]) {
+		case Top, Bottom:
+			content.Width = max(content.Width, size.Width)
+			content.Height += size.Height + d.Config.Spacing
+		case Left, Right:
+			content.Width += size.Width + d.Config.Spacing
+			content.Height = max(content.Height, size.Height)
+		}
+	}
+	return content
+}
+
+// place works out the frame of each child, inside its margin, for a dock
+// occupying frame.
+func (d *Dock) place(frame box.Frame, sizes []doodad.Rectangle) []box.Frame {
+	padding := d.Config.Padding
+	remaining := box.Frame{
+		X:      frame.X + float64(padding.Left),
+		Y:      frame.Y + float64(padding.Top),
+		Width:  max(frame.Width-float64(padding.Left+padding.Right), 0),
+		Height: max(frame.Height-float64(padding.Top+padding.Bottom), 0),
+	}
+	spacing := float64(d.Config.Spacing)
+
+	slots := make([]box.Frame, len(sizes))
+	for i, child := range d.Children().Doodads {
+		if child.IsCollapsed() || d.side(child) == Center {
+			slots[i] = box.Frame{X: remaining.X, Y: remaining.Y}
+			continue
+		}
+
+		slot := remaining
+		width := min(float64(sizes[i].Width), remaining.Width)
+		height := min(float64(sizes[i].Height), remaining.Height)
+
+		switch d.side(child) {
+		case Top:
+			slot.Height = height
+			claimed := min(height+spacing, remaining.Height)
+			remaining.Y += claimed
+			remaining.Height -= claimed
+		case Bottom:
+			slot.Height = height
+			slot.Y = remaining.Y + remaining.Height - height
+			remaining.Height -= min(height+spacing, remaining.Height)
+		case Left:
+			slot.Width = width
+			claimed := min(width+spacing, remaining.Width)
+			remaining.X += claimed
+			remaining.Width -= claimed
+		case Right:
+			slot.Width = width
+			slot.X = remaining.X + remaining.Width - width
+			remaining.Width -= min(width+spacing, remaining.Width)
+		}
+
+		slots[i] = slot
+	}
+
+	if center := d.center(); center != -1 {
+		slots[center] = remaining
+	}
+
+	for i, child := range d.Children().Doodads {
+		slots[i] = slots[i].Inset(doodad.Margin(child))
+	}
+	return slots
+}
+
+// slot returns the frame for the child at index, placing all of the children
+// again if the dock has moved or been resized since they were last placed.
+func (d *Dock) slot(index int) box.Frame {
+	frame := d.Box.Frame()
+
+	if len(d.measured) != len(d.Children().Doodads) {
+		d.measured = d.measureChildren()
+		d.slots = nil
+	}
+
+	if d.slots == nil || d.slotsFrame != frame {
+		d.slots = d.place(frame, d.measured)
+		d.slotsFrame = frame
+	}
+
+	return d.slots[index]
+}
+
+func (d *Dock) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	content := d.contentSize(d.measureChildren())
+	size := doodad.Rectangle{
+		Width:  content.Width + d.Config.Padding.Left + d.Config.Padding.Right,
+		Height: content.Height + d.Config.Padding.Top + d.Config.Padding.Bottom,
+	}
+
+	if d.Config.LayoutRule == Fill {
+		if constraints.MaxWidth != doodad.Unbounded {
+			size.Width = constraints.MaxWidth
+		}
+		if constraints.MaxHeight != doodad.Unbounded {
+			size.Height = constraints.MaxHeight
+		}
+	}
+
+	return constraints.Constrain(size)
+}
+
+func (d *Dock) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(d, rect)
+
+	for i, slot := range d.place(rect.Frame(), d.measureChildren()) {
+		doodad.Arrange(d.Children().Doodads[i], slot.Rect())
+	}
+}
@@ -0,0 +1,148 @@
+package dock
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+type child struct {
+	side      Side
+	doodad    doodad.Doodad
+	collapsed bool
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name      string
+		spacing   int
+		direction config.Direction
+		children  []child
+		expected  []box.Frame
+	}{
+		{
+			"top", 0, config.DirectionLeftToRight,
+			[]child{{side: Top, doodad: doodadtest.Fixed(50, 20)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 200, Height: 20}, {Y: 20, Width: 200, Height: 80}},
+		},
+		{
+			"bottom", 0, config.DirectionLeftToRight,
+			[]child{{side: Bottom, doodad: doodadtest.Fixed(50, 20)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Y: 80, Width: 200, Height: 20}, {Width: 200, Height: 80}},
+		},
+		{
+			"left", 0, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(40, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 40, Height: 100}, {X: 40, Width: 160, Height: 100}},
+		},
+		{
+			"right", 0, config.DirectionLeftToRight,
+			[]child{{side: Right, doodad: doodadtest.Fixed(40, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{X: 160, Width: 40, Height: 100}, {Width: 160, Height: 100}},
+		},
+		{
+			"sides in order, with spacing", 5, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(40, 30)}, {side: Top, doodad: doodadtest.Fixed(50, 20)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 40, Height: 100}, {X: 45, Width: 155, Height: 20}, {X: 45, Y: 25, Width: 155, Height: 75}},
+		},
+		{
+			"only the last center takes the remainder", 0, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(40, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 40, Height: 100}, {X: 40}, {X: 40, Width: 160, Height: 100}},
+		},
+		{
+			"a collapsed last center leaves the remainder to the one before", 0, config.DirectionLeftToRight,
+			[]child{{side: Center, doodad: doodadtest.Fixed(30, 10)}, {side: Center, doodad: doodadtest.Fixed(30, 10), collapsed: true}},
+			[]box.Frame{{Width: 200, Height: 100}, {}},
+		},
+		{
+			"a center bigger than the remainder", 0, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(180, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 180, Height: 100}, {X: 180, Width: 20, Height: 100}},
+		},
+		{
+			"collapsed sides take nothing", 5, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(40, 30), collapsed: true}, {side: Top, doodad: doodadtest.Fixed(50, 20)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{}, {Width: 200, Height: 20}, {Y: 25, Width: 200, Height: 75}},
+		},
+		{
+			"an earlier side takes the whole length", 5, config.DirectionLeftToRight,
+			[]child{{side: Left, doodad: doodadtest.Fixed(250, 30)}, {side: Right, doodad: doodadtest.Fixed(30, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{Width: 200, Height: 100}, {X: 200, Height: 100}, {X: 200, Height: 100}},
+		},
+		{
+			"right to left", 0, config.DirectionRightToLeft,
+			[]child{{side: Left, doodad: doodadtest.Fixed(40, 30)}, {side: Right, doodad: doodadtest.Fixed(20, 30)}, {side: Center, doodad: doodadtest.Fixed(30, 10)}},
+			[]box.Frame{{X: 160, Width: 40, Height: 100}, {Width: 20, Height: 100}, {X: 20, Width: 140, Height: 100}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := doodadtest.NewRoot(800, 600)
+			d := New(Config{Spacing: tt.spacing})
+			r.AddChild(d)
+			d.SetDirection(tt.direction)
+			for _, c := range tt.children {
+				d.AddChild(c.doodad)
+				d.Place(c.doodad, c.side)
+				if c.collapsed {
+					c.doodad.Collapse()
+				}
+			}
+
+			assert.Equal(t, tt.expected, d.place(box.Frame{Width: 200, Height: 100}, d.measureChildren()))
+		})
+	}
+
+	t.Run("inside the padding and margins", func(t *testing.T) {
+		r := doodadtest.NewRoot(800, 600)
+		d := New(Config{Padding: config.EqualPadding(10)})
+		r.AddChild(d)
+		side, center := doodadtest.Fixed(40, 30), doodadtest.Fixed(30, 10)
+		d.AddChild(side, center)
+		d.Place(side, Left)
+		center.Layout().SetMargin(config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4})
+
+		assert.Equal(t,
+			[]box.Frame{{X: 10, Y: 10, Width: 40, Height: 80}, {X: 54, Y: 11, Width: 134, Height: 76}},
+			d.place(box.Frame{Width: 200, Height: 100}, d.measureChildren()),
+		)
+	})
+}
+
+func TestCenterFillsTheRemainder(t *testing.T) {
+	r := doodadtest.NewRoot(200, 100)
+	d := New(Config{LayoutRule: Fill})
+	r.AddChild(d)
+	side := doodadtest.Fixed(40, 30)
+	content := stack.New(stack.Config{})
+	d.AddChild(side, content)
+	d.Place(side, Left)
+	content.AddChild(doodadtest.Fixed(50, 20))
+
+	d.Layout().Computed(func(b *box.Box) {
+		b.Copy(r.Layout())
+	})
+	doodad.Setup(d)
+	r.Layout().Recalculate()
+
+	assert.Equal(t, box.Rect{Width: 40, Height: 100}, side.Layout().Rect())
+	assert.Equal(t, box.Rect{X: 40, Width: 160, Height: 100}, content.Layout().Rect(), "a stack fitting its contents should still fill the remainder")
+}
+
+func TestContentSize(t *testing.T) {
+	r := doodadtest.NewRoot(800, 600)
+	d := New(Config{Spacing: 5})
+	r.AddChild(d)
+	side, first, last := doodadtest.Fixed(40, 30), doodadtest.Fixed(500, 500), doodadtest.Fixed(60, 50)
+	d.AddChild(side, first, last)
+	d.Place(side, Left)
+
+	assert.Equal(t, doodad.Rectangle{Width: 105, Height: 50}, d.contentSize(d.measureChildren()), "only the last center counts")
+}
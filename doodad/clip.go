@@ -0,0 +1,75 @@
+package doodad
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func (t *Default) ClipsChildren() bool {
+	return t.clipsChildren
+}
+
+// SetClipsChildren cuts the doodad's descendants off at the edges of its
+// layout: they are only drawn, and only take mouse events, inside it. The
+// doodad itself isn't clipped.
+func (t *Default) SetClipsChildren(clips bool) {
+	t.clipsChildren = clips
+}
+
+// ClipRect is the part of the screen doodad may draw in, where the layouts of
+// all of its ancestors that clip their children overlap. It is false when no
+// ancestor clips.
+func ClipRect(doodad Doodad) (image.Rectangle, bool) {
+	clip := image.Rectangle{}
+	clipped := false
+
+	for parent := doodad.Parent(); parent != nil; parent = parent.Parent() {
+		if !parent.ClipsChildren() || parent.Layout() == nil {
+			continue
+		}
+
+		r := parent.Layout().Rect()
+		rect := image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height)
+		if clipped {
+			clip = clip.Intersect(rect)
+		} else {
+			clip = rect
+			clipped = true
+		}
+	}
+
+	return clip, clipped
+}
+
+// Clipped is screen cut down to the doodad's ClipRect, keeping its
+// coordinates. It is nil when the doodad is clipped out entirely.
+func Clipped(doodad Doodad, screen *ebiten.Image) *ebiten.Image {
+	clip, ok := ClipRect(doodad)
+	if !ok {
+		return screen
+	}
+
+	clip = clip.Intersect(screen.Bounds())
+	if clip.Empty() {
+		return nil
+	}
+	return screen.SubImage(clip).(*ebiten.Image)
+}
+
+// PointIsWithin reports whether x, y is on the part of doodad that isn't
+// clipped away.
+func PointIsWithin(doodad Doodad, x, y int) bool {
+	layout := doodad.Layout()
+	if layout == nil {
+		return false
+	}
+
+	if x < layout.X() || x > layout.X()+layout.Width() ||
+		y < layout.Y() || y > layout.Y()+layout.Height() {
+		return false
+	}
+
+	clip, ok := ClipRect(doodad)
+	return !ok || image.Pt(x, y).In(clip)
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/effect"
 	"github.com/jhuggett/thingamabob/reaction"
 
 	"github.com/hajimehoshi/ebiten/v2"
@@ -19,6 +21,12 @@ type Default struct {
 	reactions *reaction.Reactions
 
 	hidden bool
+	// Collapsed doodads are hidden and give up their space in layouts.
+	collapsed bool
+	// Descendants are only drawn inside the layout.
+	clipsChildren bool
+
+	direction config.Direction
 
 	z []int
 
@@ -29,6 +37,12 @@ type Default struct {
 	statefulDoodads map[string]Doodad
 
 	background *ebiten.Image
+
+	shadows      []Shadow
+	shadowImages []*shadowImage
+
+	effects      *effect.Chain
+	effectSource *ebiten.Image
 }
 
 func (t *Default) DoOnTeardown(actions ...func()) {
@@ -48,15 +62,35 @@ func (t *Default) Update() error {
 }
 
 func (t *Default) Draw(screen *ebiten.Image) {
-	if t.hidden {
+	if t.hidden || t.Layout() == nil {
+		return
+	}
+
+	screen = Clipped(t, screen)
+	if screen == nil {
 		return
 	}
 
+	t.DrawShadows(screen)
+
+	if t.effects != nil {
+		t.drawWithEffects(screen)
+		return
+	}
+
+	x, y := t.Layout().XY()
+	t.drawContent(screen, screen, x, y)
+
+	// t.Children().Draw(screen)
+}
+
+// drawContent draws the background and cached draws onto dst with the
+// doodad's top left at x, y. Overrides always draw onto screen.
+func (t *Default) drawContent(dst *ebiten.Image, screen *ebiten.Image, x, y int) {
 	if t.background != nil {
 		op := &ebiten.DrawImageOptions{}
-		x, y := t.Layout().XY()
 		op.GeoM.Translate(float64(x), float64(y))
-		screen.DrawImage(t.background, op)
+		dst.DrawImage(t.background, op)
 	}
 
 	if cached := t.CachedDraw(); cached != nil {
@@ -69,22 +103,19 @@ func (t *Default) Draw(screen *ebiten.Image) {
 					op = draw.Op
 				}
 				op.GeoM.Translate(float64(draw.X), float64(draw.Y))
-
-				x, y := t.Layout().XY()
 				op.GeoM.Translate(float64(x), float64(y))
 
-				screen.DrawImage(draw.Image, op)
+				dst.DrawImage(draw.Image, op)
 			}
 		}
 	}
-
-	// t.Children().Draw(screen)
 }
 
 func (t *Default) Teardown() error {
 	for _, action := range t.actionOnTeardown {
 		action()
 	}
+	t.actionOnTeardown = nil
 
 	t.Reactions().Unregister()
 
@@ -94,6 +125,13 @@ func (t *Default) Teardown() error {
 
 	t.SetLayout(nil)
 
+	// The images are made again if the doodad is set up and drawn again.
+	if t.effects != nil {
+		t.effects.Dispose()
+	}
+	t.disposeEffectSource()
+	t.clearShadowImages()
+
 	t.Children().Clear()
 	return nil
 }
@@ -142,7 +180,9 @@ func (t *Default) AddChild(doodads ...Doodad) {
 			}))
 		}
 		if !t.Layout().HasDependent(doodad.Layout()) {
-			t.Layout().AddDependent(doodad.Layout())
+			if err := t.Layout().AddDependent(doodad.Layout()); err != nil {
+				slog.Warn("Child layout would depend on itself; not linking it to its parent", "child", doodad.DebugName(), "error", err)
+			}
 		}
 
 		if doodad.Reactions() == nil {
@@ -188,6 +228,24 @@ func (t *Default) SetParent(parent Doodad) {
 	t.parent = parent
 }
 
+// Direction resolves config.DirectionInherit by asking the parent, so it is
+// never DirectionInherit itself.
+func (t *Default) Direction() config.Direction {
+	if t.direction != config.DirectionInherit {
+		return t.direction
+	}
+	if t.parent != nil {
+		return t.parent.Direction()
+	}
+	return config.DirectionLeftToRight
+}
+
+// SetDirection sets the reading direction for the doodad and any descendants
+// that inherit it. Call it before Setup.
+func (t *Default) SetDirection(direction config.Direction) {
+	t.direction = direction
+}
+
 func (t *Default) Gesturer() reaction.Gesturer {
 	return t.gesturer
 }
@@ -213,12 +271,21 @@ func (t *Default) Hide() {
 	}
 }
 
+// Show also expands a collapsed doodad. Children that were collapsed on their
+// own stay collapsed.
 func (t *Default) Show() {
 	t.hidden = false
 	t.reactions.Enable()
 	// t.register()
 	for _, child := range t.Children().Doodads {
-		child.Show()
+		if !child.IsCollapsed() {
+			child.Show()
+		}
+	}
+
+	if t.collapsed {
+		t.collapsed = false
+		t.reflow()
 	}
 }
 
@@ -226,6 +293,42 @@ func (t *Default) IsVisible() bool {
 	return !t.hidden
 }
 
+// Collapse hides the doodad like Hide, and also gives up the space it takes
+// in its parent's layout. Unlike Hide, the layout is flowed again, without
+// needing another Setup. Show expands it again.
+func (t *Default) Collapse() {
+	if t.collapsed {
+		return
+	}
+
+	t.Hide()
+	t.collapsed = true
+	t.reflow()
+}
+
+func (t *Default) IsCollapsed() bool {
+	return t.collapsed
+}
+
+// reflow flags the layout of the doodad's outermost ancestor, so every
+// container around it makes or takes back room for it, and those fitting
+// their contents resize. Flagging the direct parent isn't enough: layouts
+// depend on their parent's layout, not their children's, so a parent that
+// resizes to fit wouldn't flag the containers around it. Flagging the
+// outermost layout flags every layout under it.
+func (t *Default) reflow() {
+	var outermost *box.Box
+	for parent := t.Parent(); parent != nil; parent = parent.Parent() {
+		if parent.Layout() != nil {
+			outermost = parent.Layout()
+		}
+	}
+
+	if outermost != nil {
+		outermost.FlagNeedsRecalculation()
+	}
+}
+
 func (t *Default) Background() *ebiten.Image {
 	return t.background
 }
@@ -304,7 +407,7 @@ func (t *Default) ShrinkToFitContents() {
 		return
 	}
 
-	t.Layout().Computed(func(b *box.Box) {
+	t.Layout().NamedComputed("doodad.shrink-to-fit", func(b *box.Box) {
 		boundingBox := box.Bounding(t.Children().Boxes())
 		b.CopyDimensionsOf(boundingBox)
 	})
@@ -0,0 +1,33 @@
+package doodad
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/effect"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrawWithoutALayout(t *testing.T) {
+	screen := ebiten.NewImage(10, 10)
+	for name, d := range map[string]*Default{
+		"plain":        {},
+		"with effects": {effects: effect.NewChain(effect.Effect{})},
+	} {
+		assert.NotPanics(t, func() { d.Draw(screen) }, name)
+	}
+}
+
+func TestTeardownFreesEffectImages(t *testing.T) {
+	parent := fixed(100, 100)
+	d := fixed(20, 10)
+	parent.AddChild(d)
+	d.SetEffects(effect.Effect{})
+
+	d.Draw(ebiten.NewImage(100, 100))
+	assert.NotNil(t, d.effectSource)
+
+	assert.NoError(t, d.Teardown())
+	assert.Nil(t, d.effectSource)
+	assert.Len(t, d.Effects(), 1, "the effects should be kept for the next setup")
+}
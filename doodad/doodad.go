@@ -2,6 +2,8 @@ package doodad
 
 import (
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/effect"
 	"github.com/jhuggett/thingamabob/position/box"
 	"github.com/jhuggett/thingamabob/reaction"
 )
@@ -11,10 +13,8 @@ func MouseIsWithin[T reaction.PositionedEvent](doodad Doodad) func(event T) bool
 		if !doodad.IsVisible() {
 			return false
 		}
-		layout := doodad.Layout()
 		x, y := event.XY()
-		return x >= layout.X() && x <= layout.X()+layout.Width() &&
-			y >= layout.Y() && y <= layout.Y()+layout.Height()
+		return PointIsWithin(doodad, x, y)
 	}
 }
 
@@ -23,12 +23,8 @@ func MouseIsOutside[T reaction.PositionedEvent](doodad Doodad) func(event T) boo
 		if !doodad.IsVisible() {
 			return false
 		}
-
-		layout := doodad.Layout()
 		x, y := event.XY()
-
-		return x < layout.X() || x > layout.X()+layout.Width() ||
-			y < layout.Y() || y > layout.Y()+layout.Height()
+		return !PointIsWithin(doodad, x, y)
 	}
 }
 
@@ -52,6 +48,12 @@ type Doodad interface {
 	CachedDraw() []*CachedDraw
 	SetCachedDraw(cachedDraw ...*CachedDraw)
 
+	Shadows() []Shadow
+	SetShadows(shadows ...Shadow)
+
+	Effects() []effect.Effect
+	SetEffects(effects ...effect.Effect)
+
 	// Loads up any resources that need caching, such as images or fonts.
 	Setup()
 
@@ -69,6 +71,9 @@ type Doodad interface {
 	Parent() Doodad
 	SetParent(parent Doodad)
 
+	Direction() config.Direction
+	SetDirection(direction config.Direction)
+
 	Gesturer() reaction.Gesturer
 	SetGesturer(gesturer reaction.Gesturer)
 
@@ -83,6 +88,11 @@ type Doodad interface {
 	Hide()
 	Show()
 	IsVisible() bool
+	Collapse()
+	IsCollapsed() bool
+
+	ClipsChildren() bool
+	SetClipsChildren(clips bool)
 
 	Z() []int
 	SetZ(z []int)
@@ -97,24 +107,34 @@ type Doodad interface {
 	DebugName() string
 }
 
+// Updater is implemented by doodads that change on their own over time, e.g.
+// to animate. The app calls Update once a tick, after handling input.
+type Updater interface {
+	Update() error
+}
+
 type Rectangular interface {
 	Dimensions() Rectangle
 }
 
+// Tears the doodad down and sets it up again. Layout changes made along the
+// way are batched into a single recalculation.
 func ReSetup(doodad Doodad) {
-	// We hold onto the layout so it doesn't get nuked so that we don't loose comp steps
-	ref := doodad.Layout()
-	doodad.SetLayout(box.Zeroed())
+	box.Batch(func() {
+		// We hold onto the layout so it doesn't get nuked so that we don't loose comp steps
+		ref := doodad.Layout()
+		doodad.SetLayout(box.Zeroed())
 
-	doodad.Teardown()
+		doodad.Teardown()
 
-	doodad.SetLayout(ref)
-	doodad.Layout().ClearDependents()
-	Setup(doodad)
+		doodad.SetLayout(ref)
+		doodad.Layout().ClearDependents()
+		Setup(doodad)
 
-	if doodad.Layout() != nil {
-		doodad.Layout().Recalculate()
-	}
+		if doodad.Layout() != nil {
+			doodad.Layout().Recalculate()
+		}
+	})
 }
 
 func Setup(doodads ...Doodad) {
@@ -129,3 +149,22 @@ func Setup(doodads ...Doodad) {
 		doodad.Reactions().Register(doodad.Gesturer(), doodad.Z())
 	}
 }
+
+// Restack gives d the depth z, moving its descendants along with it, so it
+// and everything in it is drawn, and gets events, at that depth. Call it
+// before Setup, as reactions are registered at the depth they have then.
+func Restack(d Doodad, z []int) {
+	old := d.Z()
+	d.SetZ(z)
+
+	if d.Children() == nil {
+		return
+	}
+	for _, child := range d.Children().Doodads {
+		childZ := child.Z()
+		if len(childZ) < len(old) {
+			continue
+		}
+		Restack(child, append(append([]int{}, z...), childZ[len(old):]...))
+	}
+}
@@ -0,0 +1,45 @@
+// Package doodadtest has doodads for testing containers without a window.
+package doodadtest
+
+import (
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/reaction"
+)
+
+// Root stands in for the top of a doodad tree, giving the doodads added to
+// it a layout, reactions and a gesturer to hang off.
+type Root struct {
+	doodad.Default
+
+	gesturer interface {
+		Trigger(reaction.ReactionType, reaction.Eventable)
+	}
+}
+
+func NewRoot(width, height int) *Root {
+	r := &Root{}
+	r.SetLayout(box.New(box.Config{Width: width, Height: height}))
+	r.SetChildren(doodad.NewChildren(r))
+	r.SetReactions(&reaction.Reactions{}, r)
+	gesturer := reaction.NewGesturer()
+	r.SetGesturer(gesturer)
+	r.gesturer = gesturer
+	return r
+}
+
+// Trigger sends a made up event to the reactions of the doodads under the
+// root, as if it came from input.
+func (r *Root) Trigger(reactionType reaction.ReactionType, data reaction.Eventable) {
+	r.gesturer.Trigger(reactionType, data)
+}
+
+// Fixed is a child that doesn't measure itself, so it is as big as its
+// layout.
+func Fixed(width, height int) *doodad.Default {
+	d := &doodad.Default{}
+	d.SetLayout(box.Computed(func(b *box.Box) {
+		b.SetDimensions(width, height)
+	}))
+	return d
+}
@@ -0,0 +1,63 @@
+package doodad
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/effect"
+)
+
+func (t *Default) Effects() []effect.Effect {
+	if t.effects == nil {
+		return nil
+	}
+	return t.effects.Effects()
+}
+
+// SetEffects runs shader effects, chained in order, over what the doodad
+// draws itself: its background and cached draws. Children draw on their own
+// and aren't affected, and neither are cached draws with an Override, which
+// draw straight to the screen. No effects turns them off.
+func (t *Default) SetEffects(effects ...effect.Effect) {
+	if t.effects != nil {
+		t.effects.Dispose()
+		t.effects = nil
+	}
+	t.disposeEffectSource()
+
+	if len(effects) > 0 {
+		t.effects = effect.NewChain(effects...)
+	}
+}
+
+// drawWithEffects draws the doodad onto an image of its own size and runs
+// the effects over it on the way to the screen.
+func (t *Default) drawWithEffects(screen *ebiten.Image) {
+	r := t.Layout().Rect()
+	if r.Width <= 0 || r.Height <= 0 {
+		return
+	}
+
+	if t.effectSource == nil || t.effectSource.Bounds().Dx() != r.Width || t.effectSource.Bounds().Dy() != r.Height {
+		t.disposeEffectSource()
+		t.effectSource = ebiten.NewImage(r.Width, r.Height)
+	}
+
+	t.effectSource.Clear()
+	t.drawContent(t.effectSource, screen, 0, 0)
+
+	ctx := effect.Context{Width: float64(r.Width), Height: float64(r.Height)}
+	if t.Gesturer() != nil {
+		mx, my := t.Gesturer().CurrentMouseLocation()
+		ctx.MouseX, ctx.MouseY = float64(mx-r.X), float64(my-r.Y)
+	}
+
+	geoM := ebiten.GeoM{}
+	geoM.Translate(float64(r.X), float64(r.Y))
+	t.effects.Draw(screen, t.effectSource, ctx, geoM)
+}
+
+func (t *Default) disposeEffectSource() {
+	if t.effectSource != nil {
+		t.effectSource.Deallocate()
+		t.effectSource = nil
+	}
+}
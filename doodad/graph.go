@@ -0,0 +1,182 @@
+package doodad
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// Graph is a snapshot of a doodad tree and the box dependency graph behind
+// it, for attaching to issues or diffing between commits. IDs are paths in
+// the tree ("0.2" is the third child of the first child) rather than
+// pointers, so the same layout always exports the same way.
+type Graph struct {
+	Doodads []GraphDoodad `json:"doodads"`
+	Boxes   []GraphBox    `json:"boxes"`
+}
+
+type GraphDoodad struct {
+	ID        string `json:"id"`
+	Parent    string `json:"parent,omitempty"`
+	Name      string `json:"name"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	Collapsed bool   `json:"collapsed,omitempty"`
+	// Empty if the doodad has no layout.
+	Box string `json:"box,omitempty"`
+}
+
+type GraphBox struct {
+	// The ID of the doodad whose layout this is, or "box-N" for boxes only
+	// reachable as dependents.
+	ID     string `json:"id"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Step names in the order they run, empty for unnamed steps.
+	Steps      []string `json:"steps"`
+	Dependents []string `json:"dependents"`
+}
+
+// NewGraph walks root, its descendants, and every box their layouts affect.
+func NewGraph(root Doodad) *Graph {
+	g := &Graph{Doodads: []GraphDoodad{}, Boxes: []GraphBox{}}
+
+	ids := map[*box.Box]string{}
+	boxes := []*box.Box{}
+
+	var walk func(d Doodad, id, parent string)
+	walk = func(d Doodad, id, parent string) {
+		node := GraphDoodad{ID: id, Parent: parent, Name: debugName(d), Hidden: !d.IsVisible(), Collapsed: d.IsCollapsed()}
+
+		if layout := d.Layout(); layout != nil {
+			if existing, ok := ids[layout]; ok {
+				node.Box = existing
+			} else {
+				ids[layout] = id
+				boxes = append(boxes, layout)
+				node.Box = id
+			}
+		}
+		g.Doodads = append(g.Doodads, node)
+
+		if d.Children() == nil {
+			return
+		}
+		for i, child := range d.Children().Doodads {
+			walk(child, fmt.Sprintf("%s.%d", id, i), id)
+		}
+	}
+	walk(root, "0", "")
+
+	// Boxes that only show up as dependents are numbered in the order they
+	// are found.
+	unowned := 0
+	for i := 0; i < len(boxes); i++ {
+		for _, dependent := range boxes[i].Dependents() {
+			if _, ok := ids[dependent]; !ok {
+				ids[dependent] = fmt.Sprintf("box-%d", unowned)
+				unowned++
+				boxes = append(boxes, dependent)
+			}
+		}
+	}
+
+	for _, b := range boxes {
+		r := b.Rect()
+		node := GraphBox{
+			ID:         ids[b],
+			X:          r.X,
+			Y:          r.Y,
+			Width:      r.Width,
+			Height:     r.Height,
+			Steps:      []string{},
+			Dependents: []string{},
+		}
+		for _, step := range b.CalculationSteps() {
+			node.Steps = append(node.Steps, step.Name())
+		}
+		for _, dependent := range b.Dependents() {
+			node.Dependents = append(node.Dependents, ids[dependent])
+		}
+		g.Boxes = append(g.Boxes, node)
+	}
+
+	return g
+}
+
+// JSON is indented so exports diff line by line.
+func (g *Graph) JSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
+
+// DOT renders the graph for Graphviz. Solid edges go from parent to child
+// doodad, dashed edges from a box to the boxes that depend on it.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+
+	sb.WriteString("digraph layout {\n")
+	sb.WriteString("  node [shape=box, fontname=monospace];\n")
+
+	boxes := map[string]GraphBox{}
+	for _, b := range g.Boxes {
+		boxes[b.ID] = b
+	}
+
+	drawn := map[string]bool{}
+	for _, d := range g.Doodads {
+		label := d.Name
+		if d.Collapsed {
+			label += " (collapsed)"
+		} else if d.Hidden {
+			label += " (hidden)"
+		}
+		if b, ok := boxes[d.Box]; ok && !drawn[d.Box] {
+			label += "\n" + b.describe()
+			drawn[d.Box] = true
+		}
+		sb.WriteString(fmt.Sprintf("  %s [label=%s];\n", dotQuote(d.ID), dotQuote(label)))
+
+		if d.Parent != "" {
+			sb.WriteString(fmt.Sprintf("  %s -> %s;\n", dotQuote(d.Parent), dotQuote(d.ID)))
+		}
+	}
+
+	for _, b := range g.Boxes {
+		if !drawn[b.ID] {
+			sb.WriteString(fmt.Sprintf("  %s [label=%s, style=rounded];\n", dotQuote(b.ID), dotQuote(b.ID+"\n"+b.describe())))
+		}
+	}
+
+	for _, b := range g.Boxes {
+		for _, dependent := range b.Dependents {
+			sb.WriteString(fmt.Sprintf("  %s -> %s [style=dashed];\n", dotQuote(b.ID), dotQuote(dependent)))
+		}
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (b GraphBox) describe() string {
+	return fmt.Sprintf("(%d, %d) %dx%d\nsteps: %d", b.X, b.Y, b.Width, b.Height, len(b.Steps))
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// debugName falls back to the doodad's type when it doesn't override
+// Default's DebugName, which only knows about Default.
+func debugName(d Doodad) string {
+	name := d.DebugName()
+	if name == fmt.Sprintf("%T", &Default{}) {
+		return fmt.Sprintf("%T", d)
+	}
+	return name
+}
@@ -0,0 +1,77 @@
+package doodad
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/reaction"
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// named has a debug name that needs escaping in DOT.
+type named struct{ Default }
+
+func (n *named) DebugName() string {
+	return `say "hi" \ wave`
+}
+
+// graphTree is a root with a laid out child, a hidden child that takes its
+// layout from the root, and a chain of two boxes no doodad owns.
+func graphTree() Doodad {
+	root := &Default{}
+	root.SetLayout(box.Zeroed())
+	root.Layout().NamedComputed("root.size", func(b *box.Box) {
+		b.SetDimensions(200, 100)
+	})
+	root.SetChildren(NewChildren(root))
+	root.SetReactions(&reaction.Reactions{}, root)
+
+	sized := &Default{}
+	sized.SetLayout(box.Computed(func(b *box.Box) {
+		b.SetDimensions(50, 20)
+	}))
+	sized.Layout().NamedComputed("stack.position", func(b *box.Box) {
+		b.SetX(10)
+	})
+
+	hidden := &named{}
+	root.AddChild(sized, hidden)
+	hidden.Hide()
+
+	first := box.Computed(func(b *box.Box) {
+		b.SetDimensions(5, 5)
+	})
+	second := box.Zeroed()
+	sized.Layout().AddDependent(first)
+	first.AddDependent(second)
+
+	root.Layout().Recalculate()
+	return root
+}
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		assert.NoError(t, os.WriteFile(path, actual, 0o644))
+	}
+	expected, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(actual))
+}
+
+func TestGraphDOT(t *testing.T) {
+	assertGolden(t, "graph.dot", []byte(NewGraph(graphTree()).DOT()))
+}
+
+func TestGraphJSON(t *testing.T) {
+	data, err := NewGraph(graphTree()).JSON()
+	assert.NoError(t, err)
+	assertGolden(t, "graph.json", append(data, '\n'))
+}
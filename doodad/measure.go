@@ -0,0 +1,164 @@
+package doodad
+
+import (
+	"math"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// Unbounded is used as a maximum when there is no limit on a dimension.
+const Unbounded = math.MaxInt
+
+// Constraints bound the size a doodad may take in the measure pass.
+type Constraints struct {
+	MinWidth  int
+	MinHeight int
+	MaxWidth  int
+	MaxHeight int
+}
+
+// Loose constraints allow any size up to width by height.
+func Loose(width, height int) Constraints {
+	return Constraints{MaxWidth: width, MaxHeight: height}
+}
+
+// Tight constraints only allow exactly width by height.
+func Tight(width, height int) Constraints {
+	return Constraints{MinWidth: width, MinHeight: height, MaxWidth: width, MaxHeight: height}
+}
+
+// UnboundedConstraints allow any size at all.
+func UnboundedConstraints() Constraints {
+	return Loose(Unbounded, Unbounded)
+}
+
+// Constrain clamps size to fit the constraints.
+func (c Constraints) Constrain(size Rectangle) Rectangle {
+	return Rectangle{
+		Width:  max(c.MinWidth, min(c.MaxWidth, size.Width)),
+		Height: max(c.MinHeight, min(c.MaxHeight, size.Height)),
+	}
+}
+
+// Deflate removes padding from the constraints, e.g. to get the space left
+// over for a container's children.
+func (c Constraints) Deflate(padding config.Padding) Constraints {
+	shrink := func(value, amount int) int {
+		if value == Unbounded {
+			return Unbounded
+		}
+		return max(value-amount, 0)
+	}
+
+	horizontal := padding.Left + padding.Right
+	vertical := padding.Top + padding.Bottom
+
+	return Constraints{
+		MinWidth:  shrink(c.MinWidth, horizontal),
+		MinHeight: shrink(c.MinHeight, vertical),
+		MaxWidth:  shrink(c.MaxWidth, horizontal),
+		MaxHeight: shrink(c.MaxHeight, vertical),
+	}
+}
+
+// Loosen drops the minimums, letting children be smaller than their parent.
+func (c Constraints) Loosen() Constraints {
+	return Constraints{MaxWidth: c.MaxWidth, MaxHeight: c.MaxHeight}
+}
+
+// Measurer is implemented by doodads that take part in the two pass layout.
+// First the parent measures each child with the space available, then it
+// arranges each child in the rect it has decided to give it.
+type Measurer interface {
+	// Measure returns the size the doodad wants within the constraints.
+	Measure(constraints Constraints) Rectangle
+
+	// Arrange positions the doodad, and its children, in rect.
+	Arrange(rect box.Rect)
+}
+
+// Measure asks doodad for its size. Doodads that don't implement Measurer are
+// as big as their current layout. Either way the size respects the limits set
+// on the doodad's layout, as far as constraints allow.
+func Measure(doodad Doodad, constraints Constraints) Rectangle {
+	layout := doodad.Layout()
+
+	var size Rectangle
+	if measurer, ok := doodad.(Measurer); ok {
+		size = measurer.Measure(constraints)
+	} else if layout != nil {
+		size = Rectangle{Width: layout.Width(), Height: layout.Height()}
+	}
+
+	if layout != nil && !layout.Limits().IsZero() {
+		width, height := layout.Limits().Apply(float64(size.Width), float64(size.Height))
+		size = Rectangle{Width: int(math.Round(width)), Height: int(math.Round(height))}
+	}
+
+	return constraints.Constrain(size)
+}
+
+// Margin is the margin set on doodad's layout, if it has one.
+func Margin(doodad Doodad) config.Padding {
+	if doodad.Layout() == nil {
+		return config.Padding{}
+	}
+	return doodad.Layout().Margin()
+}
+
+// MeasureWithMargin measures doodad in what is left of constraints once its
+// margin is taken off, and returns the size with the margin added back: the
+// space a container has to set aside for it. Collapsed doodads don't need any.
+func MeasureWithMargin(doodad Doodad, constraints Constraints) Rectangle {
+	if doodad.IsCollapsed() {
+		return Rectangle{}
+	}
+
+	margin := Margin(doodad)
+	size := Measure(doodad, constraints.Deflate(margin))
+	return Rectangle{
+		Width:  size.Width + margin.Left + margin.Right,
+		Height: size.Height + margin.Top + margin.Bottom,
+	}
+}
+
+// Arrange puts doodad in rect. Doodads that don't implement Measurer just
+// have their layout set to rect.
+func Arrange(doodad Doodad, rect box.Rect) {
+	if measurer, ok := doodad.(Measurer); ok {
+		measurer.Arrange(rect)
+		return
+	}
+
+	ArrangeLayout(doodad, rect)
+}
+
+const arrangeStep = "doodad.arrange"
+
+// ArrangeLayout sets the doodad's layout to rect with a step that runs after
+// its other steps. Measurers call it from Arrange for their own layout.
+func ArrangeLayout(doodad Doodad, rect box.Rect) {
+	if doodad.Layout() == nil {
+		return
+	}
+
+	// Re-added rather than replaced so it stays after any steps added since.
+	layout := doodad.Layout()
+	if step := layout.Step(arrangeStep); step != nil {
+		layout.RemoveStep(step)
+	}
+	layout.NamedComputed(arrangeStep, func(b *box.Box) {
+		b.SetRect(rect)
+	})
+}
+
+// LayoutWithin runs both passes for doodad, giving it as much of rect as it
+// asks for, anchored at rect's origin.
+func LayoutWithin(doodad Doodad, rect box.Rect) {
+	size := Measure(doodad, Loose(rect.Width, rect.Height))
+
+	box.Batch(func() {
+		Arrange(doodad, box.Rect{X: rect.X, Y: rect.Y, Width: size.Width, Height: size.Height})
+	})
+}
@@ -0,0 +1,189 @@
+package doodad
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/reaction"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixed is a doodad that doesn't measure itself, so it is as big as its
+// layout.
+func fixed(width, height int) *Default {
+	d := &Default{Box: box.New(box.Config{Width: width, Height: height})}
+	d.SetChildren(NewChildren(d))
+	d.SetReactions(&reaction.Reactions{}, d)
+	return d
+}
+
+// measurer wants a set size, and remembers what it was offered.
+type measurer struct {
+	Default
+
+	size        Rectangle
+	constraints Constraints
+	arranged    box.Rect
+}
+
+func newMeasurer(width, height int) *measurer {
+	m := &measurer{size: Rectangle{Width: width, Height: height}}
+	m.SetLayout(box.Zeroed())
+	m.SetChildren(NewChildren(m))
+	m.SetReactions(&reaction.Reactions{}, m)
+	return m
+}
+
+func (m *measurer) Measure(constraints Constraints) Rectangle {
+	m.constraints = constraints
+	return constraints.Constrain(m.size)
+}
+
+func (m *measurer) Arrange(rect box.Rect) {
+	m.arranged = rect
+	ArrangeLayout(m, rect)
+}
+
+func TestConstraints(t *testing.T) {
+	t.Run("constrain", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			constraints Constraints
+			size        Rectangle
+			expected    Rectangle
+		}{
+			{"within", Loose(100, 100), Rectangle{50, 60}, Rectangle{50, 60}},
+			{"too big", Loose(100, 100), Rectangle{150, 60}, Rectangle{100, 60}},
+			{"too small", Constraints{MinWidth: 20, MinHeight: 30, MaxWidth: 100, MaxHeight: 100}, Rectangle{10, 10}, Rectangle{20, 30}},
+			{"tight", Tight(40, 50), Rectangle{10, 90}, Rectangle{40, 50}},
+			{"unbounded", UnboundedConstraints(), Rectangle{5000, 6000}, Rectangle{5000, 6000}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, tt.constraints.Constrain(tt.size))
+			})
+		}
+	})
+
+	t.Run("deflate", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			constraints Constraints
+			padding     config.Padding
+			expected    Constraints
+		}{
+			{"loose", Loose(100, 50), config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4}, Loose(94, 46)},
+			{"tight", Tight(100, 50), config.EqualPadding(10), Tight(80, 30)},
+			{"unbounded stays unbounded", UnboundedConstraints(), config.EqualPadding(10), UnboundedConstraints()},
+			{"never negative", Tight(10, 10), config.EqualPadding(20), Tight(0, 0)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Equal(t, tt.expected, tt.constraints.Deflate(tt.padding))
+			})
+		}
+	})
+
+	t.Run("loosen", func(t *testing.T) {
+		assert.Equal(t, Loose(40, 50), Tight(40, 50).Loosen())
+	})
+}
+
+func TestMeasure(t *testing.T) {
+	t.Run("uses the layout of doodads that don't measure themselves", func(t *testing.T) {
+		assert.Equal(t, Rectangle{30, 40}, Measure(fixed(30, 40), UnboundedConstraints()))
+		assert.Equal(t, Rectangle{20, 40}, Measure(fixed(30, 40), Loose(20, 100)))
+	})
+
+	t.Run("asks measurers", func(t *testing.T) {
+		m := newMeasurer(30, 40)
+		assert.Equal(t, Rectangle{30, 35}, Measure(m, Loose(100, 35)))
+		assert.Equal(t, Loose(100, 35), m.constraints)
+	})
+
+	t.Run("applies the layout's limits", func(t *testing.T) {
+		m := newMeasurer(30, 40)
+		m.Layout().SetMaxSize(20, 0)
+		assert.Equal(t, Rectangle{20, 40}, Measure(m, UnboundedConstraints()))
+
+		m.Layout().SetMinSize(60, 0)
+		assert.Equal(t, Rectangle{50, 40}, Measure(m, Loose(50, 50)), "constraints still win")
+	})
+}
+
+func TestMeasureWithMargin(t *testing.T) {
+	tests := []struct {
+		name        string
+		margin      config.Padding
+		constraints Constraints
+		offered     Constraints
+		expected    Rectangle
+	}{
+		{"no margin", config.Padding{}, Loose(100, 100), Loose(100, 100), Rectangle{30, 40}},
+		{"margin is added", config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4}, Loose(100, 100), Loose(94, 96), Rectangle{36, 44}},
+		{"margin comes out of the constraints", config.EqualPadding(10), Loose(40, 100), Loose(20, 80), Rectangle{40, 60}},
+		{"unbounded", config.EqualPadding(5), UnboundedConstraints(), UnboundedConstraints(), Rectangle{40, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMeasurer(30, 40)
+			m.Layout().SetMargin(tt.margin)
+
+			assert.Equal(t, tt.expected, MeasureWithMargin(m, tt.constraints))
+			assert.Equal(t, tt.offered, m.constraints)
+		})
+	}
+
+	t.Run("collapsed doodads need no room", func(t *testing.T) {
+		d := fixed(30, 40)
+		d.Layout().SetMargin(config.EqualPadding(5))
+		d.Collapse()
+		assert.Equal(t, Rectangle{}, MeasureWithMargin(d, UnboundedConstraints()))
+	})
+}
+
+func TestArrange(t *testing.T) {
+	t.Run("measurers arrange themselves", func(t *testing.T) {
+		m := newMeasurer(30, 40)
+		Arrange(m, box.Rect{X: 5, Y: 6, Width: 70, Height: 80})
+
+		assert.Equal(t, box.Rect{X: 5, Y: 6, Width: 70, Height: 80}, m.arranged)
+		assert.Equal(t, box.Rect{X: 5, Y: 6, Width: 70, Height: 80}, m.Layout().Rect())
+	})
+
+	t.Run("other doodads get the rect as their layout", func(t *testing.T) {
+		d := fixed(30, 40)
+		Arrange(d, box.Rect{X: 5, Y: 6, Width: 70, Height: 80})
+		assert.Equal(t, box.Rect{X: 5, Y: 6, Width: 70, Height: 80}, d.Layout().Rect())
+	})
+}
+
+func TestArrangeLayout(t *testing.T) {
+	d := fixed(30, 40)
+	d.Layout().Computed(func(b *box.Box) {
+		b.SetX(100)
+	})
+
+	ArrangeLayout(d, box.Rect{X: 1, Y: 2, Width: 3, Height: 4})
+	d.Layout().Recalculate()
+	assert.Equal(t, box.Rect{X: 1, Y: 2, Width: 3, Height: 4}, d.Layout().Rect(), "runs after the existing steps")
+
+	d.Layout().Computed(func(b *box.Box) {
+		b.SetWidth(99)
+	})
+	ArrangeLayout(d, box.Rect{X: 10, Y: 20, Width: 30, Height: 40})
+	d.Layout().Recalculate()
+	assert.Equal(t, box.Rect{X: 10, Y: 20, Width: 30, Height: 40}, d.Layout().Rect(), "is moved after steps added since")
+
+	steps := 0
+	for _, step := range d.Layout().CalculationSteps() {
+		if step.Name() == arrangeStep {
+			steps++
+		}
+	}
+	assert.Equal(t, 1, steps, "replaces the last arrange")
+}
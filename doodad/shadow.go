@@ -0,0 +1,183 @@
+package doodad
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/paint"
+)
+
+// Shadow is drawn under a doodad in the shape of its layout. Without an
+// offset it is an outer glow.
+type Shadow struct {
+	OffsetX float64
+	OffsetY float64
+	// How far the edge of the shadow fades out over, in pixels.
+	Blur float64
+	// Grows the shape of the shadow before it is blurred, or shrinks it when
+	// negative.
+	Spread float64
+	Color  color.Color
+
+	// Rounds the shape, e.g. to match a rounded background. Spread grows the
+	// radii along with the shape.
+	Radius paint.Corners
+}
+
+// shadowImage is a shadow rendered for one size of doodad.
+type shadowImage struct {
+	width, height int
+
+	image *ebiten.Image
+	// Where the image goes relative to the doodad.
+	x, y float64
+}
+
+// render draws the shadow for a doodad of width by height. The shape's edge
+// is faded with the error function over its distance from each pixel, which
+// is what a gaussian blur does to a straight edge, so nothing has to be read
+// back from the GPU.
+func (s Shadow) render(width, height int) *shadowImage {
+	rendered := &shadowImage{width: width, height: height}
+	if width <= 0 || height <= 0 || s.Color == nil {
+		return rendered
+	}
+
+	pad := s.pad()
+	imageWidth, imageHeight := width+2*int(pad), height+2*int(pad)
+
+	halfWidth := max(float64(width)/2+s.Spread, 0)
+	halfHeight := max(float64(height)/2+s.Spread, 0)
+	centerX, centerY := float64(imageWidth)/2, float64(imageHeight)/2
+
+	grow := func(radius float64) float64 {
+		if radius <= 0 {
+			return 0
+		}
+		return max(radius+s.Spread, 0)
+	}
+	radii := paint.Corners{
+		TopLeft:     grow(s.Radius.TopLeft),
+		TopRight:    grow(s.Radius.TopRight),
+		BottomRight: grow(s.Radius.BottomRight),
+		BottomLeft:  grow(s.Radius.BottomLeft),
+	}
+
+	r, g, b, a := s.Color.RGBA()
+	sigma := s.sigma()
+
+	pixels := make([]byte, 4*imageWidth*imageHeight)
+	for y := 0; y < imageHeight; y++ {
+		for x := 0; x < imageWidth; x++ {
+			distance := roundedRectDistance(float64(x)+0.5-centerX, float64(y)+0.5-centerY, halfWidth, halfHeight, radii)
+			covered := coverage(distance, sigma)
+
+			i := 4 * (y*imageWidth + x)
+			pixels[i] = byte(float64(r>>8) * covered)
+			pixels[i+1] = byte(float64(g>>8) * covered)
+			pixels[i+2] = byte(float64(b>>8) * covered)
+			pixels[i+3] = byte(float64(a>>8) * covered)
+		}
+	}
+
+	rendered.image = ebiten.NewImage(imageWidth, imageHeight)
+	rendered.image.WritePixels(pixels)
+	rendered.x = s.OffsetX - pad
+	rendered.y = s.OffsetY - pad
+	return rendered
+}
+
+// sigma is the standard deviation of the gaussian blur, which fades the edge
+// out over about Blur pixels.
+func (s Shadow) sigma() float64 {
+	return max(s.Blur, 0) / 2
+}
+
+// pad is how far the image reaches past the doodad on each side. Three sigmas
+// out the shadow is too faint to show, so it isn't cut off.
+func (s Shadow) pad() float64 {
+	return math.Ceil(3*s.sigma() + max(s.Spread, 0) + 1)
+}
+
+// coverage is how much of a pixel the shadow covers at distance from the
+// edge of its shape, negative inside, once blurred by sigma.
+func coverage(distance, sigma float64) float64 {
+	if sigma == 0 {
+		return math.Max(0, math.Min(1, 0.5-distance))
+	}
+	return 0.5 * math.Erfc(distance/(sigma*math.Sqrt2))
+}
+
+// roundedRectDistance is the signed distance from a point, relative to the
+// center of a rounded rectangle, to its edge. It is negative inside.
+func roundedRectDistance(x, y, halfWidth, halfHeight float64, radii paint.Corners) float64 {
+	radius := radii.TopLeft
+	switch {
+	case x > 0 && y > 0:
+		radius = radii.BottomRight
+	case x > 0:
+		radius = radii.TopRight
+	case y > 0:
+		radius = radii.BottomLeft
+	}
+	radius = min(radius, halfWidth, halfHeight)
+
+	qx := math.Abs(x) - halfWidth + radius
+	qy := math.Abs(y) - halfHeight + radius
+	return math.Hypot(max(qx, 0), max(qy, 0)) + min(max(qx, qy), 0) - radius
+}
+
+func (t *Default) Shadows() []Shadow {
+	return t.shadows
+}
+
+// SetShadows replaces the doodad's shadows, which are drawn in order under
+// everything else it draws. Doodads that override Draw call DrawShadows to
+// keep them.
+func (t *Default) SetShadows(shadows ...Shadow) {
+	t.shadows = shadows
+	t.clearShadowImages()
+}
+
+func (t *Default) clearShadowImages() {
+	for _, rendered := range t.shadowImages {
+		if rendered != nil && rendered.image != nil {
+			rendered.image.Deallocate()
+		}
+	}
+	t.shadowImages = nil
+}
+
+// DrawShadows draws the shadows at the doodad's position, only rendering
+// them again when its size has changed. Draw calls it first.
+func (t *Default) DrawShadows(screen *ebiten.Image) {
+	if len(t.shadows) == 0 || t.Layout() == nil {
+		return
+	}
+
+	r := t.Layout().Rect()
+	if len(t.shadowImages) != len(t.shadows) {
+		t.clearShadowImages()
+		t.shadowImages = make([]*shadowImage, len(t.shadows))
+	}
+
+	for i, shadow := range t.shadows {
+		rendered := t.shadowImages[i]
+		if rendered == nil || rendered.width != r.Width || rendered.height != r.Height {
+			if rendered != nil && rendered.image != nil {
+				rendered.image.Deallocate()
+			}
+			rendered = shadow.render(r.Width, r.Height)
+			t.shadowImages[i] = rendered
+		}
+
+		if rendered.image == nil {
+			continue
+		}
+
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(r.X)+rendered.x, float64(r.Y)+rendered.y)
+		screen.DrawImage(rendered.image, op)
+	}
+}
@@ -0,0 +1,70 @@
+package doodad
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/paint"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundedRectDistance(t *testing.T) {
+	// A 10x6 rectangle, centered on the origin.
+	square := paint.Corners{}
+	round := paint.Corners{BottomRight: 2}
+
+	tests := []struct {
+		name     string
+		x, y     float64
+		radii    paint.Corners
+		expected float64
+	}{
+		{"the center", 0, 0, square, -3},
+		{"inside, nearest the right edge", 4, 0, square, -1},
+		{"on an edge", 5, 0, square, 0},
+		{"beside an edge", 8, 0, square, 3},
+		{"past a square corner", 8, 7, square, 5},
+		{"on a rounded corner's square point", 5, 3, round, 2*1.4142135623730951 - 2},
+		{"past a rounded corner", 8, 7, round, 7.810249675906654 - 2},
+		{"the other corners stay square", -8, -7, round, 5},
+		{"radii are limited to half the shorter side", 5, 0, paint.Corners{TopRight: 10}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, roundedRectDistance(tt.x, tt.y, 5, 3, tt.radii), 1e-9)
+		})
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	tests := []struct {
+		name     string
+		distance float64
+		sigma    float64
+		expected float64
+	}{
+		{"unblurred, inside", -1, 0, 1},
+		{"unblurred, on the edge", 0, 0, 0.5},
+		{"unblurred, across the edge", 0.25, 0, 0.25},
+		{"unblurred, outside", 1, 0, 0},
+		{"blurred, on the edge", 0, 2, 0.5},
+		{"blurred, a sigma outside", 2, 2, 0.158655},
+		{"blurred, a sigma inside", -2, 2, 0.841345},
+		{"blurred, two sigmas outside", 4, 2, 0.022750},
+		{"blurred, three sigmas outside", 6, 2, 0.001350},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, coverage(tt.distance, tt.sigma), 1e-6)
+		})
+	}
+}
+
+func TestShadowPadHoldsTheBlur(t *testing.T) {
+	for _, shadow := range []Shadow{{}, {Blur: 1}, {Blur: 4}, {Blur: 10, Spread: 3}, {Blur: 30, Spread: -5}} {
+		// The outermost pixels of the image, half a pixel in from its edge.
+		distance := shadow.pad() - 0.5 - shadow.Spread
+		assert.Zero(t, byte(255*coverage(distance, shadow.sigma())), "the shadow shouldn't be cut off: %+v", shadow)
+	}
+}
@@ -0,0 +1,206 @@
+package effect
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Context is what an effect knows about the frame it is drawing.
+type Context struct {
+	// Seconds since the effect was first drawn, and since the last frame.
+	Time  float64
+	Delta float64
+
+	// The mouse relative to the top left of the doodad.
+	MouseX float64
+	MouseY float64
+
+	Width  float64
+	Height float64
+}
+
+// Most images a shader can take besides the one being drawn.
+const MaxImages = 3
+
+// Effect runs a Kage shader over what a doodad draws. The shader gets it as
+// imageSrc0, and Images as imageSrc1 onwards.
+//
+// Every shader is given whatever Uniforms returns, and the built in uniforms
+// from the Context that Uniforms doesn't supply: Time as a float32 for a
+// float, and Mouse and Size as []float32 of length 2 for a vec2. ebiten
+// ignores uniforms a shader doesn't declare, but panics on ones declared with
+// another type, so a shader using those names for something else has to
+// supply them through Uniforms.
+type Effect struct {
+	Shader *ebiten.Shader
+
+	// Called every frame for the shader's uniforms, e.g. custom values
+	// animated by the app. Can be nil.
+	Uniforms func(ctx Context) map[string]any
+
+	// Stretched to the doodad's size, since ebiten needs all of a shader's
+	// images to be the same size. At most MaxImages.
+	Images []*ebiten.Image
+}
+
+// Chain runs effects one after another, each drawing what the one before it
+// drew. Intermediate results are kept in images that are only replaced when
+// the size changes.
+type Chain struct {
+	effects []Effect
+
+	start time.Time
+	last  time.Time
+
+	buffers [2]*ebiten.Image
+	// Images for each effect, stretched to the size of the buffers.
+	stretched [][]*ebiten.Image
+}
+
+func NewChain(effects ...Effect) *Chain {
+	effects = append([]Effect{}, effects...)
+	for i, e := range effects {
+		if len(e.Images) > MaxImages {
+			slog.Warn("Effect has too many images; ignoring the extras", "effect", i, "images", len(e.Images), "max", MaxImages)
+			effects[i].Images = e.Images[:MaxImages]
+		}
+	}
+
+	return &Chain{effects: effects}
+}
+
+func (c *Chain) Effects() []Effect {
+	return c.effects
+}
+
+// Draw runs the chain over src and draws the result onto dst with geoM. Only
+// the Mouse, Width and Height of ctx are used, the chain keeps time itself.
+func (c *Chain) Draw(dst *ebiten.Image, src *ebiten.Image, ctx Context, geoM ebiten.GeoM) {
+	if len(c.effects) == 0 {
+		op := &ebiten.DrawImageOptions{GeoM: geoM}
+		dst.DrawImage(src, op)
+		return
+	}
+
+	now := time.Now()
+	if c.start.IsZero() {
+		c.start, c.last = now, now
+	}
+	ctx.Time = now.Sub(c.start).Seconds()
+	ctx.Delta = now.Sub(c.last).Seconds()
+	c.last = now
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	c.resize(width, height)
+
+	current := src
+	passes := 0
+	for i, e := range c.effects {
+		if e.Shader == nil {
+			continue
+		}
+
+		op := &ebiten.DrawRectShaderOptions{Uniforms: uniforms(e, ctx)}
+		op.Images[0] = current
+		for j, image := range c.stretched[i] {
+			op.Images[j+1] = image
+		}
+
+		if i == len(c.effects)-1 {
+			op.GeoM = geoM
+			dst.DrawRectShader(width, height, e.Shader, op)
+			return
+		}
+
+		// Alternate between the buffers so no effect reads what it draws.
+		target := c.buffers[passes%2]
+		target.Clear()
+		target.DrawRectShader(width, height, e.Shader, op)
+		current = target
+		passes++
+	}
+
+	// The last effect had no shader.
+	op := &ebiten.DrawImageOptions{GeoM: geoM}
+	dst.DrawImage(current, op)
+}
+
+// uniforms are the effect's own uniforms, with the built in ones it doesn't
+// supply added.
+func uniforms(e Effect, ctx Context) map[string]any {
+	values := map[string]any{}
+	if e.Uniforms != nil {
+		for name, value := range e.Uniforms(ctx) {
+			values[name] = value
+		}
+	}
+
+	builtIn := map[string]any{
+		"Time":  float32(ctx.Time),
+		"Mouse": []float32{float32(ctx.MouseX), float32(ctx.MouseY)},
+		"Size":  []float32{float32(ctx.Width), float32(ctx.Height)},
+	}
+	for name, value := range builtIn {
+		if _, ok := values[name]; !ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// resize replaces the buffers and stretched images when the size changes.
+func (c *Chain) resize(width, height int) {
+	if c.buffers[0] != nil && c.buffers[0].Bounds().Dx() == width && c.buffers[0].Bounds().Dy() == height {
+		return
+	}
+
+	c.Dispose()
+
+	for i := range c.buffers {
+		c.buffers[i] = ebiten.NewImage(width, height)
+	}
+
+	c.stretched = make([][]*ebiten.Image, len(c.effects))
+	for i, e := range c.effects {
+		for _, image := range e.Images {
+			c.stretched[i] = append(c.stretched[i], stretch(image, width, height))
+		}
+	}
+}
+
+// Dispose frees the chain's images. They are made again if it draws.
+func (c *Chain) Dispose() {
+	for i, buffer := range c.buffers {
+		if buffer != nil {
+			buffer.Deallocate()
+			c.buffers[i] = nil
+		}
+	}
+
+	for _, images := range c.stretched {
+		for _, image := range images {
+			image.Deallocate()
+		}
+	}
+	c.stretched = nil
+}
+
+func stretch(image *ebiten.Image, width, height int) *ebiten.Image {
+	stretched := ebiten.NewImage(width, height)
+	if image == nil {
+		return stretched
+	}
+
+	bounds := image.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return stretched
+	}
+
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	op.GeoM.Scale(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	stretched.DrawImage(image, op)
+	return stretched
+}
@@ -0,0 +1,36 @@
+package effect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUniforms(t *testing.T) {
+	ctx := Context{Time: 1.5, MouseX: 10, MouseY: 20, Width: 300, Height: 200}
+
+	t.Run("built in", func(t *testing.T) {
+		assert.Equal(t, map[string]any{
+			"Time":  float32(1.5),
+			"Mouse": []float32{10, 20},
+			"Size":  []float32{300, 200},
+		}, uniforms(Effect{}, ctx))
+	})
+
+	t.Run("supplied values win", func(t *testing.T) {
+		e := Effect{Uniforms: func(ctx Context) map[string]any {
+			return map[string]any{
+				"Mouse":    []float32{1, 2, 3, 4},
+				"Size":     float32(ctx.Width),
+				"Strength": float32(0.5),
+			}
+		}}
+
+		assert.Equal(t, map[string]any{
+			"Time":     float32(1.5),
+			"Mouse":    []float32{1, 2, 3, 4},
+			"Size":     float32(300),
+			"Strength": float32(0.5),
+		}, uniforms(e, ctx))
+	})
+}
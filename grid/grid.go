@@ -0,0 +1,124 @@
+package grid
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+type LayoutRule int
+
+const (
+	FitContents LayoutRule = iota
+	Fill
+)
+
+type Config struct {
+	// Without columns the grid has a single auto column.
+	Columns []Track
+	// Rows beyond these are added as needed, sized to their content.
+	Rows []Track
+
+	ColumnGap int
+	RowGap    int
+	Padding   config.Padding
+
+	LayoutRule LayoutRule
+
+	// How children are aligned in their cells, unless placed with a Cell
+	// that has its own alignment.
+	HorizontalAlignment config.HorizontalAlignment
+	VerticalAlignment   config.VerticalAlignment
+}
+
+// Cell is where a child goes in the grid. Rows and columns count from zero.
+type Cell struct {
+	Row    int
+	Column int
+	// Zero means one.
+	RowSpan    int
+	ColumnSpan int
+
+	// How the child is aligned in the cell. These are only used when
+	// OwnAlignment is set, otherwise the child takes the grid's alignment.
+	HorizontalAlignment config.HorizontalAlignment
+	VerticalAlignment   config.VerticalAlignment
+	OwnAlignment        bool
+}
+
+func (c Cell) spans() (rows, columns int) {
+	return max(c.RowSpan, 1), max(c.ColumnSpan, 1)
+}
+
+func New(config Config) *Grid {
+	return &Grid{
+		Config: config,
+	}
+}
+
+type Grid struct {
+	Config Config
+
+	doodad.Default
+
+	placed map[doodad.Doodad]Cell
+
+	// The cell each child ended up in, the child sizes from the last
+	// measure, and where they were placed.
+	cells      []Cell
+	measured   []doodad.Rectangle
+	slots      []box.Frame
+	slotsFrame box.Frame
+}
+
+// Place puts child in cell. Children that aren't placed fill the free cells
+// left to right, top to bottom, in the order they were added. Call it before
+// Setup.
+func (g *Grid) Place(child doodad.Doodad, cell Cell) {
+	if g.placed == nil {
+		g.placed = map[doodad.Doodad]Cell{}
+	}
+	g.placed[child] = cell
+}
+
+// Names of the calculation steps a grid owns.
+const (
+	positionStep  = "grid.position"
+	sizeChildStep = "grid.size-child"
+	sizeStep      = "grid.size"
+)
+
+func (g *Grid) Setup() {
+	for i, child := range g.Children().Doodads {
+		child.Layout().NamedComputed(positionStep, func(b *box.Box) {
+			slot := g.slot(i)
+			b.SetFrameOrigin(slot.X, slot.Y)
+		})
+	}
+
+	g.Children().Setup()
+
+	// Added after the children's own steps so stretched children keep the
+	// size of their cell.
+	for i, child := range g.Children().Doodads {
+		child.Layout().NamedComputed(sizeChildStep, func(b *box.Box) {
+			b.SetFrame(g.sizeChild(i, b.Frame(), g.slot(i)))
+		})
+	}
+
+	g.Box.NamedComputed(sizeStep, func(b *box.Box) {
+		g.cells = g.arrangeCells()
+		g.measured = g.measureChildren()
+		g.slots = nil
+
+		switch g.Config.LayoutRule {
+		case FitContents:
+			content := g.contentSize()
+			b.SetWidth(content.Width + g.Config.Padding.Left + g.Config.Padding.Right)
+			b.SetHeight(content.Height + g.Config.Padding.Top + g.Config.Padding.Bottom)
+
+		case Fill:
+			// Do nothing, we fill the available space
+		}
+	})
+}
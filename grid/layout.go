@@ -0,0 +1,274 @@
+package grid
+
+import (
+	"log/slog"
+	"math"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+func (g *Grid) columns() []Track {
+	if len(g.Config.Columns) == 0 {
+		return []Track{AutoTrack()}
+	}
+	return g.Config.Columns
+}
+
+// arrangeCells works out the cell of every child, placing the ones without a
+// cell of their own in the first free cells. Collapsed children don't take up
+// their cells.
+func (g *Grid) arrangeCells() []Cell {
+	columns := len(g.columns())
+	children := g.Children().Doodads
+
+	cells := make([]Cell, len(children))
+	taken := map[[2]int]bool{}
+	take := func(cell Cell) {
+		rows, cols := cell.spans()
+		for r := cell.Row; r < cell.Row+rows; r++ {
+			for c := cell.Column; c < cell.Column+cols; c++ {
+				taken[[2]int{r, c}] = true
+			}
+		}
+	}
+
+	for i, child := range children {
+		cell, ok := g.placed[child]
+		if !ok {
+			continue
+		}
+
+		if _, cols := cell.spans(); cell.Column < 0 || cell.Row < 0 || cell.Column+cols > columns {
+			slog.Warn("Grid cell is outside the grid's columns; clamping it", "child", child.DebugName(), "cell", cell)
+			cell.Row = max(cell.Row, 0)
+			cell.Column = min(max(cell.Column, 0), columns-1)
+			cell.ColumnSpan = min(cols, columns-cell.Column)
+		}
+		if !cell.OwnAlignment {
+			cell.HorizontalAlignment = g.Config.HorizontalAlignment
+			cell.VerticalAlignment = g.Config.VerticalAlignment
+		}
+
+		cells[i] = cell
+		if !child.IsCollapsed() {
+			take(cell)
+		}
+	}
+
+	next := 0
+	for i, child := range children {
+		if _, ok := g.placed[child]; ok {
+			continue
+		}
+
+		for taken[[2]int{next / columns, next % columns}] {
+			next++
+		}
+		cells[i] = Cell{
+			Row:                 next / columns,
+			Column:              next % columns,
+			HorizontalAlignment: g.Config.HorizontalAlignment,
+			VerticalAlignment:   g.Config.VerticalAlignment,
+		}
+		if !child.IsCollapsed() {
+			take(cells[i])
+		}
+	}
+
+	return cells
+}
+
+// measureChildren measures every child, with its margin, without limits. The
+// tracks decide how much room they actually get.
+func (g *Grid) measureChildren() []doodad.Rectangle {
+	sizes := make([]doodad.Rectangle, len(g.Children().Doodads))
+	for i, child := range g.Children().Doodads {
+		sizes[i] = doodad.MeasureWithMargin(child, doodad.UnboundedConstraints())
+	}
+	return sizes
+}
+
+// tracks sizes the columns and rows to fit inner, the space inside the
+// padding. Infinite lengths size the tracks to their content. Collapsed
+// children don't count.
+func (g *Grid) tracks(inner box.Frame) (columns []float64, rows []float64) {
+	rowTracks := append([]Track{}, g.Config.Rows...)
+	columnItems := []item{}
+	rowItems := []item{}
+
+	for i, cell := range g.cells {
+		if g.collapsed(i) {
+			continue
+		}
+
+		rowSpan, columnSpan := cell.spans()
+		for len(rowTracks) < cell.Row+rowSpan {
+			rowTracks = append(rowTracks, AutoTrack())
+		}
+
+		columnItems = append(columnItems, item{start: cell.Column, span: columnSpan, length: g.measured[i].Width})
+		rowItems = append(rowItems, item{start: cell.Row, span: rowSpan, length: g.measured[i].Height})
+	}
+
+	return sizeTracks(g.columns(), columnItems, g.Config.ColumnGap, inner.Width),
+		sizeTracks(rowTracks, rowItems, g.Config.RowGap, inner.Height)
+}
+
+// contentSize is the size of the tracks sized to their content, without the
+// grid's padding.
+func (g *Grid) contentSize() doodad.Rectangle {
+	columns, rows := g.tracks(box.Frame{Width: math.Inf(1), Height: math.Inf(1)})
+	return doodad.Rectangle{
+		Width:  int(math.Ceil(total(columns, g.Config.ColumnGap))),
+		Height: int(math.Ceil(total(rows, g.Config.RowGap))),
+	}
+}
+
+// place works out the frame of each child, inside its margin, for a grid
+// occupying frame.
+func (g *Grid) place(frame box.Frame) []box.Frame {
+	padding := g.Config.Padding
+	inner := box.Frame{
+		X:      frame.X + float64(padding.Left),
+		Y:      frame.Y + float64(padding.Top),
+		Width:  frame.Width - float64(padding.Left+padding.Right),
+		Height: frame.Height - float64(padding.Top+padding.Bottom),
+	}
+
+	length := inner
+	if g.Config.LayoutRule == FitContents {
+		length.Width, length.Height = math.Inf(1), math.Inf(1)
+	}
+	columns, rows := g.tracks(length)
+
+	slots := make([]box.Frame, len(g.cells))
+	for i, cell := range g.cells {
+		if g.collapsed(i) {
+			// Left with no size, its cell may not have a track at all.
+			slots[i] = box.Frame{X: inner.X, Y: inner.Y}
+			continue
+		}
+
+		rowSpan, columnSpan := cell.spans()
+		x, width := span(columns, g.Config.ColumnGap, cell.Column, columnSpan)
+		y, height := span(rows, g.Config.RowGap, cell.Row, rowSpan)
+
+		if g.Direction() == config.DirectionRightToLeft {
+			x = inner.Width - x - width
+		}
+
+		slot := box.Frame{Width: float64(g.measured[i].Width), Height: float64(g.measured[i].Height)}
+
+		switch g.horizontalAlignment(cell) {
+		case config.HorizontalAlignmentStretch:
+			slot.Width = width
+		case config.HorizontalAlignmentCenter:
+			x += box.Half(width - slot.Width)
+		case config.HorizontalAlignmentRight:
+			x += width - slot.Width
+		}
+
+		switch cell.VerticalAlignment {
+		case config.VerticalAlignmentStretch:
+			slot.Height = height
+		case config.VerticalAlignmentCenter:
+			y += box.Half(height - slot.Height)
+		case config.VerticalAlignmentBottom:
+			y += height - slot.Height
+		}
+
+		slot.X, slot.Y = inner.X+x, inner.Y+y
+		slots[i] = slot.Inset(doodad.Margin(g.Children().Doodads[i]))
+	}
+	return slots
+}
+
+// collapsed is true if the child at index has been collapsed out of the
+// layout.
+func (g *Grid) collapsed(index int) bool {
+	return g.Children().Doodads[index].IsCollapsed()
+}
+
+// horizontalAlignment is the cell's alignment with left and right swapped for
+// right to left content.
+func (g *Grid) horizontalAlignment(cell Cell) config.HorizontalAlignment {
+	if g.Direction() != config.DirectionRightToLeft {
+		return cell.HorizontalAlignment
+	}
+
+	switch cell.HorizontalAlignment {
+	case config.HorizontalAlignmentLeft:
+		return config.HorizontalAlignmentRight
+	case config.HorizontalAlignmentRight:
+		return config.HorizontalAlignmentLeft
+	default:
+		return cell.HorizontalAlignment
+	}
+}
+
+// slot returns the frame for the child at index, placing all of the children
+// again if the grid has moved or been resized since they were last placed.
+func (g *Grid) slot(index int) box.Frame {
+	frame := g.Box.Frame()
+
+	if len(g.measured) != len(g.Children().Doodads) {
+		g.cells = g.arrangeCells()
+		g.measured = g.measureChildren()
+		g.slots = nil
+	}
+
+	if g.slots == nil || g.slotsFrame != frame {
+		g.slots = g.place(frame)
+		g.slotsFrame = frame
+	}
+
+	return g.slots[index]
+}
+
+// sizeChild gives a stretched child the size of its cell.
+func (g *Grid) sizeChild(index int, frame box.Frame, slot box.Frame) box.Frame {
+	cell := g.cells[index]
+	if cell.HorizontalAlignment == config.HorizontalAlignmentStretch {
+		frame.Width = slot.Width
+	}
+	if cell.VerticalAlignment == config.VerticalAlignmentStretch {
+		frame.Height = slot.Height
+	}
+	return frame
+}
+
+func (g *Grid) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	g.cells = g.arrangeCells()
+	g.measured = g.measureChildren()
+	g.slots = nil
+
+	content := g.contentSize()
+	size := doodad.Rectangle{
+		Width:  content.Width + g.Config.Padding.Left + g.Config.Padding.Right,
+		Height: content.Height + g.Config.Padding.Top + g.Config.Padding.Bottom,
+	}
+
+	if g.Config.LayoutRule == Fill {
+		if constraints.MaxWidth != doodad.Unbounded {
+			size.Width = constraints.MaxWidth
+		}
+		if constraints.MaxHeight != doodad.Unbounded {
+			size.Height = constraints.MaxHeight
+		}
+	}
+
+	return constraints.Constrain(size)
+}
+
+func (g *Grid) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(g, rect)
+
+	g.cells = g.arrangeCells()
+	g.measured = g.measureChildren()
+	for i, slot := range g.place(rect.Frame()) {
+		doodad.Arrange(g.Children().Doodads[i], slot.Rect())
+	}
+	g.slots = nil
+}
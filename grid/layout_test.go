@@ -0,0 +1,165 @@
+package grid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/stretchr/testify/assert"
+)
+
+// gridWith is a grid with n children of the given size.
+func gridWith(config Config, n int, width, height int) *Grid {
+	r := doodadtest.NewRoot(800, 600)
+	g := New(config)
+	r.AddChild(g)
+	for i := 0; i < n; i++ {
+		g.AddChild(doodadtest.Fixed(width, height))
+	}
+	return g
+}
+
+func TestArrangeCells(t *testing.T) {
+	centered := Config{
+		Columns:             []Track{AutoTrack(), AutoTrack(), AutoTrack()},
+		HorizontalAlignment: config.HorizontalAlignmentCenter,
+		VerticalAlignment:   config.VerticalAlignmentBottom,
+	}
+	// Cells without their own alignment take the grid's.
+	inherited := func(cell Cell) Cell {
+		cell.HorizontalAlignment = config.HorizontalAlignmentCenter
+		cell.VerticalAlignment = config.VerticalAlignmentBottom
+		return cell
+	}
+	auto := func(row, column int) Cell {
+		return inherited(Cell{Row: row, Column: column})
+	}
+
+	tests := []struct {
+		name      string
+		config    Config
+		children  int
+		placed    map[int]Cell
+		collapsed []int
+		expected  []Cell
+	}{
+		{
+			"left to right, top to bottom",
+			centered, 4, nil, nil,
+			[]Cell{auto(0, 0), auto(0, 1), auto(0, 2), auto(1, 0)},
+		},
+		{
+			"a single auto column without columns",
+			Config{}, 2, nil, nil,
+			[]Cell{{Row: 0}, {Row: 1}},
+		},
+		{
+			"around a placed cell",
+			centered, 4, map[int]Cell{1: {Row: 0, Column: 1}}, nil,
+			[]Cell{auto(0, 0), auto(0, 1), auto(0, 2), auto(1, 0)},
+		},
+		{
+			"around a cell placed after them",
+			centered, 4, map[int]Cell{3: {Row: 0, Column: 0}}, nil,
+			[]Cell{auto(0, 1), auto(0, 2), auto(1, 0), auto(0, 0)},
+		},
+		{
+			"around spans",
+			centered, 4, map[int]Cell{0: {Row: 0, Column: 0, RowSpan: 2, ColumnSpan: 2}}, nil,
+			[]Cell{inherited(Cell{Row: 0, Column: 0, RowSpan: 2, ColumnSpan: 2}), auto(0, 2), auto(1, 2), auto(2, 0)},
+		},
+		{
+			"placed cells keep their own alignment",
+			centered, 1, map[int]Cell{0: {Row: 2, Column: 1, HorizontalAlignment: config.HorizontalAlignmentStretch, OwnAlignment: true}}, nil,
+			[]Cell{{Row: 2, Column: 1, HorizontalAlignment: config.HorizontalAlignmentStretch, OwnAlignment: true}},
+		},
+		{
+			"placed cells are clamped to the columns",
+			centered, 2, map[int]Cell{0: {Row: -1, Column: 2, ColumnSpan: 3}}, nil,
+			[]Cell{inherited(Cell{Row: 0, Column: 2, ColumnSpan: 1}), auto(0, 0)},
+		},
+		{
+			"collapsed children don't take a cell",
+			centered, 3, nil, []int{0},
+			[]Cell{auto(0, 0), auto(0, 0), auto(0, 1)},
+		},
+		{
+			"collapsed placed children don't take their cell",
+			centered, 2, map[int]Cell{0: {Row: 0, Column: 0}}, []int{0},
+			[]Cell{auto(0, 0), auto(0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gridWith(tt.config, tt.children, 10, 10)
+			for i, cell := range tt.placed {
+				g.Place(g.Children().Doodads[i], cell)
+			}
+			for _, i := range tt.collapsed {
+				g.Children().Doodads[i].Collapse()
+			}
+			assert.Equal(t, tt.expected, g.arrangeCells())
+		})
+	}
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction config.Direction
+		cell      Cell
+		expected  box.Frame
+	}{
+		{"top left", config.DirectionLeftToRight, Cell{Row: 1, Column: 1}, box.Frame{X: 75, Y: 45, Width: 20, Height: 10}},
+		{"centered", config.DirectionLeftToRight, Cell{Row: 1, Column: 1, HorizontalAlignment: config.HorizontalAlignmentCenter, VerticalAlignment: config.VerticalAlignmentCenter, OwnAlignment: true}, box.Frame{X: 125, Y: 70, Width: 20, Height: 10}},
+		{"bottom right", config.DirectionLeftToRight, Cell{Row: 1, Column: 1, HorizontalAlignment: config.HorizontalAlignmentRight, VerticalAlignment: config.VerticalAlignmentBottom, OwnAlignment: true}, box.Frame{X: 175, Y: 95, Width: 20, Height: 10}},
+		{"stretched", config.DirectionLeftToRight, Cell{Row: 1, Column: 1, HorizontalAlignment: config.HorizontalAlignmentStretch, VerticalAlignment: config.VerticalAlignmentStretch, OwnAlignment: true}, box.Frame{X: 75, Y: 45, Width: 120, Height: 60}},
+		{"spanning", config.DirectionLeftToRight, Cell{Row: 0, Column: 0, RowSpan: 2, ColumnSpan: 2, HorizontalAlignment: config.HorizontalAlignmentStretch, VerticalAlignment: config.VerticalAlignmentStretch, OwnAlignment: true}, box.Frame{X: 5, Y: 5, Width: 190, Height: 100}},
+		{"right to left", config.DirectionRightToLeft, Cell{Row: 1, Column: 1}, box.Frame{X: 105, Y: 45, Width: 20, Height: 10}},
+		{"right to left, aligned right", config.DirectionRightToLeft, Cell{Row: 1, Column: 1, HorizontalAlignment: config.HorizontalAlignmentRight, OwnAlignment: true}, box.Frame{X: 5, Y: 45, Width: 20, Height: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A 60 wide fixed column and a fraction column, a 30 high fixed
+			// row and a fraction row, in a 200x110 grid with a padding of 5
+			// and gaps of 10.
+			g := gridWith(Config{
+				Columns:    []Track{Pixels(60), Fr(1)},
+				Rows:       []Track{Pixels(30), Fr(1)},
+				ColumnGap:  10,
+				RowGap:     10,
+				Padding:    config.EqualPadding(5),
+				LayoutRule: Fill,
+			}, 1, 20, 10)
+			g.SetDirection(tt.direction)
+			g.Place(g.Children().Doodads[0], tt.cell)
+			g.cells = g.arrangeCells()
+			g.measured = g.measureChildren()
+
+			assert.Equal(t, []box.Frame{tt.expected}, g.place(box.Frame{Width: 200, Height: 110}))
+		})
+	}
+}
+
+func TestCollapsedChildrenDontSizeTracks(t *testing.T) {
+	g := gridWith(Config{Columns: []Track{AutoTrack(), AutoTrack()}, ColumnGap: 10, RowGap: 10}, 3, 20, 10)
+	wide := doodadtest.Fixed(100, 50)
+	g.AddChild(wide)
+	g.Place(wide, Cell{Row: 3, Column: 1})
+	g.Children().Doodads[1].Collapse()
+	wide.Collapse()
+
+	g.cells = g.arrangeCells()
+	g.measured = g.measureChildren()
+	columns, rows := g.tracks(box.Frame{Width: math.Inf(1), Height: math.Inf(1)})
+	assert.Equal(t, []float64{20, 20}, columns)
+	assert.Equal(t, []float64{10}, rows, "collapsed children shouldn't add rows")
+
+	slots := g.place(box.Frame{X: 5, Y: 5, Width: 200, Height: 200})
+	assert.Equal(t, box.Frame{X: 5, Y: 5}, slots[1])
+	assert.Equal(t, box.Frame{X: 5, Y: 5}, slots[3])
+}
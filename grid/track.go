@@ -0,0 +1,132 @@
+package grid
+
+import "math"
+
+type TrackKind int
+
+const (
+	// A set number of pixels.
+	Fixed TrackKind = iota
+	// A share of the space left once the other tracks are sized, relative to
+	// the other fraction tracks. A grid that fits its contents has no space
+	// left, so fraction tracks size to their content like auto tracks.
+	Fraction
+	// As big as the biggest child in the track.
+	Auto
+)
+
+// Track is a row or column definition.
+type Track struct {
+	Kind TrackKind
+	// Pixels for fixed tracks, the weight for fraction tracks.
+	Size float64
+}
+
+func Pixels(pixels int) Track {
+	return Track{Kind: Fixed, Size: float64(pixels)}
+}
+
+func Fr(weight float64) Track {
+	return Track{Kind: Fraction, Size: weight}
+}
+
+func AutoTrack() Track {
+	return Track{Kind: Auto}
+}
+
+// item is a child's extent along one axis, in tracks, and the length it
+// wants along that axis.
+type item struct {
+	start, span int
+	length      int
+}
+
+// sizeTracks works out the length of each track. length is the space for the
+// tracks and the gaps between them, +Inf when the grid fits its contents.
+func sizeTracks(tracks []Track, items []item, gap int, length float64) []float64 {
+	sizes := make([]float64, len(tracks))
+	fitsContent := func(t Track) bool {
+		return t.Kind == Auto || (t.Kind == Fraction && math.IsInf(length, 1))
+	}
+
+	for i, t := range tracks {
+		if t.Kind == Fixed {
+			sizes[i] = t.Size
+		}
+	}
+
+	for _, it := range items {
+		if it.span == 1 && fitsContent(tracks[it.start]) {
+			sizes[it.start] = max(sizes[it.start], float64(it.length))
+		}
+	}
+
+	// Children spanning several tracks share any length they still need
+	// between the tracks in their span that size to content, unless a
+	// fraction track in the span will take up the slack.
+	for _, it := range items {
+		if it.span == 1 {
+			continue
+		}
+
+		current := float64(gap * (it.span - 1))
+		growable := []int{}
+		flexible := false
+		for i := it.start; i < it.start+it.span; i++ {
+			current += sizes[i]
+			if fitsContent(tracks[i]) {
+				growable = append(growable, i)
+			} else if tracks[i].Kind == Fraction {
+				flexible = true
+			}
+		}
+
+		if extra := float64(it.length) - current; extra > 0 && len(growable) > 0 && !flexible {
+			for _, i := range growable {
+				sizes[i] += extra / float64(len(growable))
+			}
+		}
+	}
+
+	if math.IsInf(length, 1) {
+		return sizes
+	}
+
+	free := length - float64(gap*max(len(tracks)-1, 0))
+	weights := 0.0
+	for i, t := range tracks {
+		if t.Kind == Fraction {
+			weights += t.Size
+		} else {
+			free -= sizes[i]
+		}
+	}
+	if weights <= 0 {
+		return sizes
+	}
+
+	for i, t := range tracks {
+		if t.Kind == Fraction {
+			sizes[i] = max(free*t.Size/weights, 0)
+		}
+	}
+	return sizes
+}
+
+// total is the length of the tracks and the gaps between them.
+func total(sizes []float64, gap int) float64 {
+	length := float64(gap * max(len(sizes)-1, 0))
+	for _, size := range sizes {
+		length += size
+	}
+	return length
+}
+
+// span returns where the tracks [start, start+n) begin, measured from the
+// first track, and how long they are together.
+func span(sizes []float64, gap int, start, n int) (offset float64, length float64) {
+	for i := 0; i < start; i++ {
+		offset += sizes[i] + float64(gap)
+	}
+	return offset, total(sizes[start:start+n], gap)
+}
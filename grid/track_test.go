@@ -0,0 +1,52 @@
+package grid
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizeTracks(t *testing.T) {
+	unbounded := math.Inf(1)
+
+	tests := []struct {
+		name     string
+		tracks   []Track
+		items    []item
+		gap      int
+		length   float64
+		expected []float64
+	}{
+		{"fixed", []Track{Pixels(20), Pixels(30)}, []item{{0, 1, 80}}, 5, unbounded, []float64{20, 30}},
+		{"auto fits the biggest child", []Track{AutoTrack(), AutoTrack()}, []item{{0, 1, 40}, {0, 1, 25}, {1, 1, 10}}, 0, unbounded, []float64{40, 10}},
+		{"empty auto", []Track{AutoTrack(), Pixels(10)}, nil, 0, 100, []float64{0, 10}},
+		{"fractions fit their content when unbounded", []Track{Fr(1), Fr(2)}, []item{{0, 1, 30}, {1, 1, 20}}, 10, unbounded, []float64{30, 20}},
+		{"fractions share what the others leave", []Track{Pixels(20), AutoTrack(), Fr(1), Fr(3)}, []item{{1, 1, 30}, {2, 1, 500}}, 10, 250, []float64{20, 30, 42.5, 127.5}},
+		{"fractions get nothing when there is nothing left", []Track{Pixels(100), Fr(1)}, nil, 0, 50, []float64{100, 0}},
+		{"a span shares its extra length", []Track{AutoTrack(), AutoTrack()}, []item{{0, 2, 50}}, 10, unbounded, []float64{20, 20}},
+		{"a span only grows what it needs", []Track{AutoTrack(), AutoTrack()}, []item{{0, 1, 10}, {0, 2, 50}}, 0, unbounded, []float64{30, 20}},
+		{"a span doesn't grow fixed tracks", []Track{Pixels(10), AutoTrack()}, []item{{0, 2, 50}}, 0, unbounded, []float64{10, 40}},
+		{"a span that already fits", []Track{Pixels(30), Pixels(30)}, []item{{0, 2, 50}}, 0, unbounded, []float64{30, 30}},
+		{"a span across a fraction leaves it the slack", []Track{AutoTrack(), Fr(1)}, []item{{0, 2, 100}}, 0, 200, []float64{0, 200}},
+		{"a span across a fraction fitting its content", []Track{AutoTrack(), Fr(1)}, []item{{0, 2, 100}}, 0, unbounded, []float64{50, 50}},
+		{"fractions after a span", []Track{AutoTrack(), AutoTrack(), Fr(1)}, []item{{0, 2, 60}}, 10, 200, []float64{25, 25, 130}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDeltaSlice(t, tt.expected, sizeTracks(tt.tracks, tt.items, tt.gap, tt.length), 1e-9)
+		})
+	}
+}
+
+func TestSpan(t *testing.T) {
+	sizes := []float64{10, 20, 30}
+
+	offset, length := span(sizes, 5, 1, 2)
+	assert.Equal(t, 15.0, offset)
+	assert.Equal(t, 55.0, length)
+
+	assert.Equal(t, 70.0, total(sizes, 5))
+	assert.Equal(t, 0.0, total(nil, 5))
+}
@@ -72,7 +72,7 @@ type Label struct {
 // 	}
 // }
 
-func (w *Label) Setup() {
+func (w *Label) textFace() *text.GoTextFace {
 	if w.fontSource == nil {
 		var err error
 		w.fontSource, err = text.NewGoTextFaceSource(bytes.NewReader(goregular.TTF))
@@ -81,28 +81,59 @@ func (w *Label) Setup() {
 		}
 	}
 
-	textFace := &text.GoTextFace{
+	return &text.GoTextFace{
 		Source: w.fontSource,
 		Size:   float64(w.Config.FontSize),
 	}
+}
 
+// The size of the message plus padding.
+func (w *Label) intrinsicSize() doodad.Rectangle {
 	width, height := text.Measure(
 		w.Config.Message,
-		textFace,
+		w.textFace(),
 		0,
 	)
 
-	w.Layout().Computed(func(b *box.Box) {
-		b.SetDimensions(
-			int(width)+w.Config.Padding.Left+w.Config.Padding.Right,
-			int(height)+w.Config.Padding.Top+w.Config.Padding.Bottom,
-		)
+	return doodad.Rectangle{
+		Width:  int(width) + w.Config.Padding.Left + w.Config.Padding.Right,
+		Height: int(height) + w.Config.Padding.Top + w.Config.Padding.Bottom,
+	}
+}
+
+func (w *Label) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	return constraints.Constrain(w.intrinsicSize())
+}
+
+func (w *Label) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(w, rect)
+}
+
+func (w *Label) Setup() {
+	size := w.intrinsicSize()
+
+	w.Layout().NamedComputed("label.size", func(b *box.Box) {
+		b.SetDimensions(size.Width, size.Height)
 	})
 
-	if w.Layout().IsACompleteDegenerate() {
+	w.render()
+
+	// Parents may size the label after its own step, e.g. a stretching stack.
+	w.DoOnTeardown(w.Layout().OnChange(func(old, new box.Rect) {
+		if old.Width != new.Width || old.Height != new.Height {
+			w.render()
+		}
+	}))
+}
+
+// render draws the message into the cached draw at the label's current size.
+func (w *Label) render() {
+	if w.Layout().Width() <= 0 || w.Layout().Height() <= 0 {
+		w.SetCachedDraw()
 		return
 	}
 
+	textFace := w.textFace()
 	img := ebiten.NewImage(w.Layout().Width(), w.Layout().Height())
 
 	if w.Config.BackgroundColor != nil {
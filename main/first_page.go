@@ -7,6 +7,7 @@ import (
 	"github.com/jhuggett/thingamabob/app"
 	"github.com/jhuggett/thingamabob/button"
 	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/dock"
 	"github.com/jhuggett/thingamabob/doodad"
 	"github.com/jhuggett/thingamabob/label"
 	"github.com/jhuggett/thingamabob/position/box"
@@ -32,8 +33,10 @@ type firstPage struct {
 
 func (p *firstPage) Setup() {
 
+	layout := dock.New(dock.Config{LayoutRule: dock.Fill})
+	p.AddChild(layout)
+
 	navBar := NewNavBar(p.App)
-	p.AddChild(navBar)
 
 	titleLabel := label.New(label.Config{
 		Message:  "First Page",
@@ -113,7 +116,8 @@ func (p *firstPage) Setup() {
 		})
 	})
 
-	p.AddChild(mainStack)
+	layout.AddChild(navBar, mainStack)
+	layout.Place(navBar, dock.Left)
 
 	mainStack.AddChild(
 		titleLabel,
@@ -155,14 +159,6 @@ func (p *firstPage) Setup() {
 
 	p.AddChild(toggleButton)
 
-	contentPane := box.Computed(func(b *box.Box) {
-		b.Copy(p.Box).DecreaseWidth(navBar.Box.Width()).MoveRight(navBar.Box.Width())
-	})
-
-	mainStack.Layout().Computed(func(b *box.Box) {
-		b.AlignTopWithin(contentPane).AlignLeftWithin(contentPane)
-	})
-
 	p.Children().Setup()
 
 	toggleButton.Layout().Computed(func(b *box.Box) {
@@ -79,6 +79,9 @@ func (n *NavBar) Setup() {
 
 		LayoutRule: stack.Fill,
 
+		// Every button is as wide as the nav bar.
+		HorizontalAlignment: config.HorizontalAlignmentStretch,
+
 		// BOXES seem to not be taking padding into account. NEED TO FIGURE THIS OUT
 	})
 	n.AddChild(mainStack)
@@ -4,9 +4,9 @@ import (
 	"image/color"
 
 	"github.com/jhuggett/thingamabob/app"
+	"github.com/jhuggett/thingamabob/dock"
 	"github.com/jhuggett/thingamabob/doodad"
 	"github.com/jhuggett/thingamabob/label"
-	"github.com/jhuggett/thingamabob/position/box"
 	"github.com/jhuggett/thingamabob/stack"
 )
 
@@ -28,26 +28,20 @@ type SecondPage struct {
 }
 
 func (p *SecondPage) Setup() {
-	nav := NewNavBar(p.App)
-	p.AddChild(nav)
+	layout := dock.New(dock.Config{LayoutRule: dock.Fill})
+	p.AddChild(layout)
 
+	nav := NewNavBar(p.App)
 	contentStack := stack.New(stack.Config{
 		BackgroundColor: color.RGBA{100, 200, 120, 100},
 	})
-	p.AddChild(contentStack)
+	layout.AddChild(nav, contentStack)
+	layout.Place(nav, dock.Left)
 
 	contentStack.AddChild(label.New(label.Config{
 		Message: "This is the Second page",
 	}))
 
-	contentPane := box.Computed(func(b *box.Box) {
-		b.Copy(p.Box).DecreaseWidth(nav.Box.Width()).MoveRight(nav.Box.Width())
-	})
-
-	contentStack.Layout().Computed(func(b *box.Box) {
-		b.AlignTopWithin(contentPane).AlignLeftWithin(contentPane)
-	})
-
 	p.Children().Setup()
 
 }
@@ -1,8 +1,15 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/jhuggett/thingamabob/app"
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/dock"
 	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/label"
+	"github.com/jhuggett/thingamabob/scroll"
+	"github.com/jhuggett/thingamabob/stack"
 )
 
 func NewThirdPage(
@@ -23,8 +30,31 @@ type ThirdPage struct {
 }
 
 func (p *ThirdPage) Setup() {
+	layout := dock.New(dock.Config{LayoutRule: dock.Fill})
+	p.AddChild(layout)
+
 	nav := NewNavBar(p.App)
-	p.AddChild(nav)
+	log := scroll.New(scroll.Config{
+		Scrollbars: true,
+		Keyboard:   true,
+		Drag:       true,
+		Momentum:   true,
+	})
+	layout.AddChild(nav, log)
+	layout.Place(nav, dock.Left)
+
+	lines := stack.New(stack.Config{
+		Flow:         config.TopToBottom,
+		SpaceBetween: 4,
+		Padding:      config.EqualPadding(8),
+	})
+	log.AddChild(lines)
+
+	for i := 1; i <= 100; i++ {
+		lines.AddChild(label.New(label.Config{
+			Message: fmt.Sprintf("Log line %d", i),
+		}))
+	}
 
 	p.Children().Setup()
 }
@@ -0,0 +1,130 @@
+package overlay
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+func (o *Overlay) measureChildren() []doodad.Rectangle {
+	sizes := make([]doodad.Rectangle, len(o.Children().Doodads))
+	for i, child := range o.Children().Doodads {
+		sizes[i] = doodad.MeasureWithMargin(child, doodad.UnboundedConstraints())
+	}
+	return sizes
+}
+
+// contentSize is the size of the largest child in each direction, without
+// the overlay's padding. Offsets don't count.
+func (o *Overlay) contentSize(sizes []doodad.Rectangle) doodad.Rectangle {
+	content := doodad.Rectangle{}
+	for _, size := range sizes {
+		content.Width = max(content.Width, size.Width)
+		content.Height = max(content.Height, size.Height)
+	}
+	return content
+}
+
+// place works out the frame of each child, inside its margin, for an overlay
+// occupying frame.
+func (o *Overlay) place(frame box.Frame, sizes []doodad.Rectangle) []box.Frame {
+	padding := o.Config.Padding
+	inner := box.Frame{
+		X:      frame.X + float64(padding.Left),
+		Y:      frame.Y + float64(padding.Top),
+		Width:  frame.Width - float64(padding.Left+padding.Right),
+		Height: frame.Height - float64(padding.Top+padding.Bottom),
+	}
+	rightToLeft := o.Direction() == config.DirectionRightToLeft
+
+	slots := make([]box.Frame, len(sizes))
+	for i, child := range o.Children().Doodads {
+		layer := o.layers[child]
+		horizontal, vertical := layer.Anchor.alignments()
+		offsetX := float64(layer.OffsetX)
+
+		// Anchors and offsets are mirrored for right to left content.
+		if rightToLeft {
+			offsetX = -offsetX
+			switch horizontal {
+			case config.HorizontalAlignmentLeft:
+				horizontal = config.HorizontalAlignmentRight
+			case config.HorizontalAlignmentRight:
+				horizontal = config.HorizontalAlignmentLeft
+			}
+		}
+
+		slot := box.Frame{
+			X:      inner.X + offsetX,
+			Y:      inner.Y + float64(layer.OffsetY),
+			Width:  float64(sizes[i].Width),
+			Height: float64(sizes[i].Height),
+		}
+
+		switch horizontal {
+		case config.HorizontalAlignmentStretch:
+			slot.Width = inner.Width
+		case config.HorizontalAlignmentCenter:
+			slot.X += box.Half(inner.Width - slot.Width)
+		case config.HorizontalAlignmentRight:
+			slot.X += inner.Width - slot.Width
+		}
+
+		switch vertical {
+		case config.VerticalAlignmentStretch:
+			slot.Height = inner.Height
+		case config.VerticalAlignmentCenter:
+			slot.Y += box.Half(inner.Height - slot.Height)
+		case config.VerticalAlignmentBottom:
+			slot.Y += inner.Height - slot.Height
+		}
+
+		slots[i] = slot.Inset(doodad.Margin(child))
+	}
+	return slots
+}
+
+// slot returns the frame for the child at index, placing all of the children
+// again if the overlay has moved or been resized since they were last placed.
+func (o *Overlay) slot(index int) box.Frame {
+	frame := o.Box.Frame()
+
+	if len(o.measured) != len(o.Children().Doodads) {
+		o.measured = o.measureChildren()
+		o.slots = nil
+	}
+
+	if o.slots == nil || o.slotsFrame != frame {
+		o.slots = o.place(frame, o.measured)
+		o.slotsFrame = frame
+	}
+
+	return o.slots[index]
+}
+
+func (o *Overlay) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	content := o.contentSize(o.measureChildren())
+	size := doodad.Rectangle{
+		Width:  content.Width + o.Config.Padding.Left + o.Config.Padding.Right,
+		Height: content.Height + o.Config.Padding.Top + o.Config.Padding.Bottom,
+	}
+
+	if o.Config.LayoutRule == Fill {
+		if constraints.MaxWidth != doodad.Unbounded {
+			size.Width = constraints.MaxWidth
+		}
+		if constraints.MaxHeight != doodad.Unbounded {
+			size.Height = constraints.MaxHeight
+		}
+	}
+
+	return constraints.Constrain(size)
+}
+
+func (o *Overlay) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(o, rect)
+
+	for i, slot := range o.place(rect.Frame(), o.measureChildren()) {
+		doodad.Arrange(o.Children().Doodads[i], slot.Rect())
+	}
+}
@@ -0,0 +1,76 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/stretchr/testify/assert"
+)
+
+// overlayWith is an overlay with a child for each layer, for testing the
+// layout math on its own. The children's sizes come from the sizes passed
+// in, not their layouts.
+func overlayWith(config Config, layers ...Layer) *Overlay {
+	r := doodadtest.NewRoot(800, 600)
+	o := New(config)
+	r.AddChild(o)
+	for _, layer := range layers {
+		child := &doodad.Default{}
+		child.SetLayout(box.Zeroed())
+		o.AddChild(child)
+		o.Place(child, layer)
+	}
+	return o
+}
+
+func TestPlace(t *testing.T) {
+	// A 20x10 child in a 120x100 overlay with a padding of 10, which leaves
+	// 100x80 inside it.
+	frame := box.Frame{Width: 120, Height: 100}
+	size := []doodad.Rectangle{{Width: 20, Height: 10}}
+
+	tests := []struct {
+		name      string
+		layer     Layer
+		direction config.Direction
+		expected  box.Frame
+	}{
+		{"top left", Layer{Anchor: TopLeft}, config.DirectionLeftToRight, box.Frame{X: 10, Y: 10, Width: 20, Height: 10}},
+		{"top", Layer{Anchor: Top}, config.DirectionLeftToRight, box.Frame{X: 50, Y: 10, Width: 20, Height: 10}},
+		{"top right", Layer{Anchor: TopRight}, config.DirectionLeftToRight, box.Frame{X: 90, Y: 10, Width: 20, Height: 10}},
+		{"left", Layer{Anchor: Left}, config.DirectionLeftToRight, box.Frame{X: 10, Y: 45, Width: 20, Height: 10}},
+		{"center", Layer{Anchor: Center}, config.DirectionLeftToRight, box.Frame{X: 50, Y: 45, Width: 20, Height: 10}},
+		{"right", Layer{Anchor: Right}, config.DirectionLeftToRight, box.Frame{X: 90, Y: 45, Width: 20, Height: 10}},
+		{"bottom left", Layer{Anchor: BottomLeft}, config.DirectionLeftToRight, box.Frame{X: 10, Y: 80, Width: 20, Height: 10}},
+		{"bottom", Layer{Anchor: Bottom}, config.DirectionLeftToRight, box.Frame{X: 50, Y: 80, Width: 20, Height: 10}},
+		{"bottom right", Layer{Anchor: BottomRight}, config.DirectionLeftToRight, box.Frame{X: 90, Y: 80, Width: 20, Height: 10}},
+		{"cover", Layer{Anchor: Cover}, config.DirectionLeftToRight, box.Frame{X: 10, Y: 10, Width: 100, Height: 80}},
+		{"offset", Layer{Anchor: BottomRight, OffsetX: -5, OffsetY: 3}, config.DirectionLeftToRight, box.Frame{X: 85, Y: 83, Width: 20, Height: 10}},
+		{"right to left", Layer{Anchor: TopLeft}, config.DirectionRightToLeft, box.Frame{X: 90, Y: 10, Width: 20, Height: 10}},
+		{"right to left, centered", Layer{Anchor: Center}, config.DirectionRightToLeft, box.Frame{X: 50, Y: 45, Width: 20, Height: 10}},
+		{"right to left, offset", Layer{Anchor: Right, OffsetX: 5}, config.DirectionRightToLeft, box.Frame{X: 5, Y: 45, Width: 20, Height: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := overlayWith(Config{Padding: config.EqualPadding(10)}, tt.layer)
+			o.SetDirection(tt.direction)
+			assert.Equal(t, []box.Frame{tt.expected}, o.place(frame, size))
+		})
+	}
+
+	t.Run("inside the margin", func(t *testing.T) {
+		o := overlayWith(Config{Padding: config.EqualPadding(10)}, Layer{Anchor: Cover})
+		o.Children().Doodads[0].Layout().SetMargin(config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4})
+		assert.Equal(t, []box.Frame{{X: 14, Y: 11, Width: 94, Height: 76}}, o.place(frame, size))
+	})
+}
+
+func TestContentSize(t *testing.T) {
+	o := overlayWith(Config{}, Layer{}, Layer{Anchor: Center, OffsetX: 100})
+	sizes := []doodad.Rectangle{{Width: 20, Height: 50}, {Width: 40, Height: 10}}
+	assert.Equal(t, doodad.Rectangle{Width: 40, Height: 50}, o.contentSize(sizes))
+}
@@ -0,0 +1,140 @@
+package overlay
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+type LayoutRule int
+
+const (
+	// As big as the largest child.
+	FitContents LayoutRule = iota
+	Fill
+)
+
+type Config struct {
+	Padding    config.Padding
+	LayoutRule LayoutRule
+}
+
+// Anchor is the point of the overlay a child is pinned to.
+type Anchor int
+
+const (
+	TopLeft Anchor = iota
+	Top
+	TopRight
+	Left
+	Center
+	Right
+	BottomLeft
+	Bottom
+	BottomRight
+	// The child covers the whole overlay, inside the padding.
+	Cover
+)
+
+func (a Anchor) alignments() (config.HorizontalAlignment, config.VerticalAlignment) {
+	if a == Cover {
+		return config.HorizontalAlignmentStretch, config.VerticalAlignmentStretch
+	}
+
+	horizontal := []config.HorizontalAlignment{
+		config.HorizontalAlignmentLeft,
+		config.HorizontalAlignmentCenter,
+		config.HorizontalAlignmentRight,
+	}[a%3]
+	vertical := []config.VerticalAlignment{
+		config.VerticalAlignmentTop,
+		config.VerticalAlignmentCenter,
+		config.VerticalAlignmentBottom,
+	}[a/3]
+	return horizontal, vertical
+}
+
+// Layer is how a child sits in the overlay. The offset moves it from its
+// anchor, right and down for positive values.
+type Layer struct {
+	Anchor  Anchor
+	OffsetX int
+	OffsetY int
+}
+
+func New(config Config) *Overlay {
+	return &Overlay{
+		Config: config,
+	}
+}
+
+// Overlay layers its children in the same rect. Children added later are
+// drawn, and get events, above the ones added before them.
+type Overlay struct {
+	Config Config
+
+	doodad.Default
+
+	layers map[doodad.Doodad]Layer
+
+	// Child sizes from the last measure, and where they were placed.
+	measured   []doodad.Rectangle
+	slots      []box.Frame
+	slotsFrame box.Frame
+}
+
+// Place sets how child sits in the overlay. Children that aren't placed are
+// anchored to the top left. Call it before Setup.
+func (o *Overlay) Place(child doodad.Doodad, layer Layer) {
+	if o.layers == nil {
+		o.layers = map[doodad.Doodad]Layer{}
+	}
+	o.layers[child] = layer
+}
+
+// Names of the calculation steps an overlay owns.
+const (
+	positionStep  = "overlay.position"
+	sizeChildStep = "overlay.size-child"
+	sizeStep      = "overlay.size"
+)
+
+func (o *Overlay) Setup() {
+	for i, child := range o.Children().Doodads {
+		doodad.Restack(child, append(append([]int{}, o.Z()...), i))
+
+		child.Layout().NamedComputed(positionStep, func(b *box.Box) {
+			slot := o.slot(i)
+			b.SetFrameOrigin(slot.X, slot.Y)
+		})
+	}
+
+	o.Children().Setup()
+
+	// Added after the children's own steps so covering children keep the
+	// overlay's size.
+	for i, child := range o.Children().Doodads {
+		if o.layers[child].Anchor != Cover {
+			continue
+		}
+		child.Layout().NamedComputed(sizeChildStep, func(b *box.Box) {
+			slot := o.slot(i)
+			b.SetFrame(box.Frame{X: slot.X, Y: slot.Y, Width: slot.Width, Height: slot.Height})
+		})
+	}
+
+	o.Box.NamedComputed(sizeStep, func(b *box.Box) {
+		o.measured = o.measureChildren()
+		o.slots = nil
+
+		switch o.Config.LayoutRule {
+		case FitContents:
+			content := o.contentSize(o.measured)
+			b.SetWidth(content.Width + o.Config.Padding.Left + o.Config.Padding.Right)
+			b.SetHeight(content.Height + o.Config.Padding.Top + o.Config.Padding.Bottom)
+
+		case Fill:
+			// Do nothing, we fill the available space
+		}
+	})
+}
@@ -0,0 +1,157 @@
+package paint
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Draw paints style onto dst, filling a box of width by height at its origin.
+func Draw(dst *ebiten.Image, width, height float64, style Style) {
+	if width <= 0 || height <= 0 || style.IsZero() {
+		return
+	}
+
+	outer := rect{width: float32(width), height: float32(height)}
+	outerRadii := radii{
+		{float32(style.Radius.TopLeft), float32(style.Radius.TopLeft)},
+		{float32(style.Radius.TopRight), float32(style.Radius.TopRight)},
+		{float32(style.Radius.BottomRight), float32(style.Radius.BottomRight)},
+		{float32(style.Radius.BottomLeft), float32(style.Radius.BottomLeft)},
+	}.fit(outer.width, outer.height)
+
+	antialias := !style.Aliased
+
+	if style.Color != nil {
+		path := &vector.Path{}
+		appendRoundedRect(path, outer, outerRadii)
+		fill(dst, path, style.Color, antialias, vector.FillRuleNonZero)
+	}
+
+	if style.Source != nil {
+		drawSource(dst, outer, outerRadii, style.Source, antialias)
+	}
+
+	if !style.Border.Exists() {
+		return
+	}
+
+	inner, innerRadii := innerEdge(outer, outerRadii, style.Border)
+
+	// The border is everything between the outer and inner edges.
+	ring := &vector.Path{}
+	appendRoundedRect(ring, outer, outerRadii)
+	if inner.width > 0 && inner.height > 0 {
+		appendRoundedRect(ring, inner, innerRadii)
+	}
+
+	if style.Border.uniform() {
+		for _, edge := range style.Border.edges() {
+			if edge.visible() {
+				fill(dst, ring, edge.Color, antialias, vector.FillRuleEvenOdd)
+				break
+			}
+		}
+		return
+	}
+
+	// Each side gets the part of the ring between the lines joining the
+	// outer and inner corners, which is masked out on scratch images.
+	bounds := dst.Bounds()
+	side := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	mask := ebiten.NewImage(bounds.Dx(), bounds.Dy())
+	defer side.Deallocate()
+	defer mask.Deallocate()
+
+	for i, edge := range style.Border.edges() {
+		if !edge.visible() {
+			continue
+		}
+
+		side.Clear()
+		fill(side, ring, edge.Color, antialias, vector.FillRuleEvenOdd)
+
+		mask.Clear()
+		quad := &vector.Path{}
+		appendQuad(quad, sideQuad(i, outer, inner))
+		fill(mask, quad, color.White, antialias, vector.FillRuleNonZero)
+
+		side.DrawImage(mask, &ebiten.DrawImageOptions{Blend: ebiten.BlendDestinationIn})
+		dst.DrawImage(side, nil)
+	}
+}
+
+// drawSource fills a scratch image with source and cuts the corners off it
+// before drawing it onto dst.
+func drawSource(dst *ebiten.Image, outer rect, outerRadii radii, source Source, antialias bool) {
+	width, height := int(math.Ceil(float64(outer.width))), int(math.Ceil(float64(outer.height)))
+
+	layer := ebiten.NewImage(width, height)
+	defer layer.Deallocate()
+	source.Fill(layer, width, height)
+
+	if outerRadii != (radii{}) {
+		mask := ebiten.NewImage(width, height)
+		defer mask.Deallocate()
+
+		path := &vector.Path{}
+		appendRoundedRect(path, outer, outerRadii)
+		fill(mask, path, color.White, antialias, vector.FillRuleNonZero)
+		layer.DrawImage(mask, &ebiten.DrawImageOptions{Blend: ebiten.BlendDestinationIn})
+	}
+
+	dst.DrawImage(layer, nil)
+}
+
+func fill(dst *ebiten.Image, path *vector.Path, clr color.Color, antialias bool, rule vector.FillRule) {
+	options := &vector.DrawPathOptions{AntiAlias: antialias}
+	options.ColorScale.ScaleWithColor(clr)
+	vector.FillPath(dst, path, &vector.FillOptions{FillRule: rule}, options)
+}
+
+// innerEdge is the inside edge of the border. Its corners are the outer
+// corners shrunk by the widths of the sides that meet there, so they turn
+// elliptical when those widths differ.
+func innerEdge(outer rect, outerRadii radii, border Border) (rect, radii) {
+	top, right := float32(max(border.Top.Width, 0)), float32(max(border.Right.Width, 0))
+	bottom, left := float32(max(border.Bottom.Width, 0)), float32(max(border.Left.Width, 0))
+
+	inner := rect{
+		x:      outer.x + left,
+		y:      outer.y + top,
+		width:  max(outer.width-left-right, 0),
+		height: max(outer.height-top-bottom, 0),
+	}
+
+	shrink := func(radius [2]float32, horizontal, vertical float32) [2]float32 {
+		return [2]float32{max(radius[0]-horizontal, 0), max(radius[1]-vertical, 0)}
+	}
+	return inner, radii{
+		shrink(outerRadii[0], left, top),
+		shrink(outerRadii[1], right, top),
+		shrink(outerRadii[2], right, bottom),
+		shrink(outerRadii[3], left, bottom),
+	}
+}
+
+// sideQuad is the area belonging to one side of the border, indexed like
+// Border.edges: the outer and inner edges of that side joined at the
+// corners.
+func sideQuad(side int, outer, inner rect) [4][2]float32 {
+	o := corners(outer)
+	in := corners(inner)
+	next := (side + 1) % 4
+	return [4][2]float32{o[side], o[next], in[next], in[side]}
+}
+
+// corners of r clockwise from the top left.
+func corners(r rect) [4][2]float32 {
+	return [4][2]float32{
+		{r.x, r.y},
+		{r.x + r.width, r.y},
+		{r.x + r.width, r.y + r.height},
+		{r.x, r.y + r.height},
+	}
+}
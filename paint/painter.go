@@ -0,0 +1,55 @@
+package paint
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// Painter keeps a painted background around for a doodad, only painting it
+// again when the size or style changes.
+type Painter struct {
+	style Style
+
+	image *ebiten.Image
+	dirty bool
+}
+
+func NewPainter(style Style) *Painter {
+	return &Painter{style: style, dirty: true}
+}
+
+func (p *Painter) Style() Style {
+	return p.style
+}
+
+func (p *Painter) SetStyle(style Style) {
+	p.style = style
+	p.dirty = true
+}
+
+// Paint returns the background for a box of width by height. The image is
+// reused, and repainted in place, for as long as the size stays the same. It
+// is nil when there is nothing to paint.
+func (p *Painter) Paint(width, height int) *ebiten.Image {
+	if width <= 0 || height <= 0 || p.style.IsZero() {
+		return nil
+	}
+
+	if p.image != nil {
+		bounds := p.image.Bounds()
+		if bounds.Dx() != width || bounds.Dy() != height {
+			p.image.Deallocate()
+			p.image = nil
+		}
+	}
+
+	if p.image == nil {
+		p.image = ebiten.NewImage(width, height)
+		p.dirty = true
+	}
+
+	if p.dirty {
+		p.image.Clear()
+		Draw(p.image, float64(width), float64(height), p.style)
+		p.dirty = false
+	}
+
+	return p.image
+}
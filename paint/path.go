@@ -0,0 +1,96 @@
+package paint
+
+import (
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// How far along the tangents the control points of a cubic Bézier quarter
+// ellipse go.
+const kappa = 0.5522847498
+
+type rect struct {
+	x, y, width, height float32
+}
+
+// radii are the horizontal and vertical radius of each corner, in the order
+// top left, top right, bottom right, bottom left.
+type radii [4][2]float32
+
+// fit scales the radii down, all by the same amount, until adjacent corners
+// no longer overlap.
+func (r radii) fit(width, height float32) radii {
+	scale := float32(1)
+	for _, pair := range [][2]float32{
+		{width, r[0][0] + r[1][0]},
+		{width, r[3][0] + r[2][0]},
+		{height, r[0][1] + r[3][1]},
+		{height, r[1][1] + r[2][1]},
+	} {
+		if pair[1] > 0 {
+			scale = min(scale, pair[0]/pair[1])
+		}
+	}
+
+	if scale < 1 {
+		for i := range r {
+			r[i][0] *= scale
+			r[i][1] *= scale
+		}
+	}
+	return r
+}
+
+// appendRoundedRect adds a closed rounded rectangle to path, clockwise from
+// the top left.
+func appendRoundedRect(path *vector.Path, r rect, corners radii) {
+	corners = corners.fit(r.width, r.height)
+	left, top := r.x, r.y
+	right, bottom := r.x+r.width, r.y+r.height
+
+	tl, tr, br, bl := corners[0], corners[1], corners[2], corners[3]
+
+	path.MoveTo(left+tl[0], top)
+	path.LineTo(right-tr[0], top)
+	appendCorner(path, right-tr[0], top, right, top+tr[1], tr, true)
+	path.LineTo(right, bottom-br[1])
+	appendCorner(path, right, bottom-br[1], right-br[0], bottom, br, false)
+	path.LineTo(left+bl[0], bottom)
+	appendCorner(path, left+bl[0], bottom, left, bottom-bl[1], bl, true)
+	path.LineTo(left, top+tl[1])
+	appendCorner(path, left, top+tl[1], left+tl[0], top, tl, false)
+	path.Close()
+}
+
+// appendCorner curves from (x0, y0) to (x1, y1) around a corner. The curve
+// leaves horizontally and arrives vertically when horizontalFirst is set, and
+// the other way around otherwise.
+func appendCorner(path *vector.Path, x0, y0, x1, y1 float32, radius [2]float32, horizontalFirst bool) {
+	if radius[0] <= 0 || radius[1] <= 0 {
+		path.LineTo(x1, y1)
+		return
+	}
+
+	if horizontalFirst {
+		path.CubicTo(
+			x0+(x1-x0)*kappa, y0,
+			x1, y1-(y1-y0)*kappa,
+			x1, y1,
+		)
+		return
+	}
+
+	path.CubicTo(
+		x0, y0+(y1-y0)*kappa,
+		x1-(x1-x0)*kappa, y1,
+		x1, y1,
+	)
+}
+
+// appendQuad adds a closed four sided polygon to path.
+func appendQuad(path *vector.Path, points [4][2]float32) {
+	path.MoveTo(points[0][0], points[0][1])
+	for _, p := range points[1:] {
+		path.LineTo(p[0], p[1])
+	}
+	path.Close()
+}
@@ -0,0 +1,215 @@
+package paint
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/config"
+)
+
+// Source fills a background with something other than a flat color.
+type Source interface {
+	// Fill paints the source over all of dst, which is width by height.
+	Fill(dst *ebiten.Image, width, height int)
+}
+
+// Stop is a color at an offset, from 0 to 1, along a gradient.
+type Stop struct {
+	Offset float64
+	Color  color.Color
+}
+
+// LinearGradient blends between its stops along a line through the middle
+// of the box at Angle degrees: 0 runs left to right, 90 top to bottom. The
+// line is long enough for the stops to reach the corners.
+type LinearGradient struct {
+	Angle float64
+	Stops []Stop
+}
+
+func (g LinearGradient) Fill(dst *ebiten.Image, width, height int) {
+	radians := g.Angle * math.Pi / 180
+	dx, dy := math.Cos(radians), math.Sin(radians)
+
+	// Half the length of the box projected onto the line.
+	half := (math.Abs(dx)*float64(width) + math.Abs(dy)*float64(height)) / 2
+	if half == 0 {
+		return
+	}
+
+	cx, cy := float64(width)/2, float64(height)/2
+	fillPixels(dst, width, height, g.Stops, func(x, y float64) float64 {
+		return ((x-cx)*dx+(y-cy)*dy)/(2*half) + 0.5
+	})
+}
+
+// RadialGradient blends between its stops outwards from a center. The center
+// is relative to the box, so 0.5, 0.5 is the middle. Radius is in pixels,
+// zero reaches the farthest corner.
+type RadialGradient struct {
+	CenterX float64
+	CenterY float64
+	Radius  float64
+	Stops   []Stop
+}
+
+func (g RadialGradient) Fill(dst *ebiten.Image, width, height int) {
+	cx, cy := g.CenterX*float64(width), g.CenterY*float64(height)
+
+	radius := g.Radius
+	if radius <= 0 {
+		for _, corner := range [][2]float64{{0, 0}, {float64(width), 0}, {0, float64(height)}, {float64(width), float64(height)}} {
+			radius = max(radius, math.Hypot(corner[0]-cx, corner[1]-cy))
+		}
+	}
+	if radius == 0 {
+		return
+	}
+
+	fillPixels(dst, width, height, g.Stops, func(x, y float64) float64 {
+		return math.Hypot(x-cx, y-cy) / radius
+	})
+}
+
+// fillPixels colors each pixel by where offset puts its center along the
+// stops.
+func fillPixels(dst *ebiten.Image, width, height int, stops []Stop, offset func(x, y float64) float64) {
+	if len(stops) == 0 {
+		return
+	}
+
+	stops = append([]Stop{}, stops...)
+	sort.SliceStable(stops, func(i, j int) bool { return stops[i].Offset < stops[j].Offset })
+
+	pixels := make([]byte, 4*width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := blend(stops, offset(float64(x)+0.5, float64(y)+0.5))
+			i := 4 * (y*width + x)
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = r, g, b, a
+		}
+	}
+
+	layer := ebiten.NewImage(width, height)
+	defer layer.Deallocate()
+	layer.WritePixels(pixels)
+	dst.DrawImage(layer, nil)
+}
+
+// blend returns the premultiplied color at offset t between the stops, which
+// are sorted.
+func blend(stops []Stop, t float64) (r, g, b, a byte) {
+	if t <= stops[0].Offset {
+		return premultiplied(stops[0].Color)
+	}
+	last := stops[len(stops)-1]
+	if t >= last.Offset {
+		return premultiplied(last.Color)
+	}
+
+	i := sort.Search(len(stops), func(i int) bool { return stops[i].Offset > t })
+	from, to := stops[i-1], stops[i]
+	amount := (t - from.Offset) / (to.Offset - from.Offset)
+
+	fr, fg, fb, fa := from.Color.RGBA()
+	tr, tg, tb, ta := to.Color.RGBA()
+	mix := func(a, b uint32) byte {
+		return byte((float64(a)+(float64(b)-float64(a))*amount)/257 + 0.5)
+	}
+	return mix(fr, tr), mix(fg, tg), mix(fb, tb), mix(fa, ta)
+}
+
+func premultiplied(c color.Color) (r, g, b, a byte) {
+	if c == nil {
+		return 0, 0, 0, 0
+	}
+	cr, cg, cb, ca := c.RGBA()
+	return byte(cr >> 8), byte(cg >> 8), byte(cb >> 8), byte(ca >> 8)
+}
+
+// Tiled repeats an image from the top left of the box, cutting off the tiles
+// at the right and bottom edges.
+type Tiled struct {
+	Image *ebiten.Image
+}
+
+func (t Tiled) Fill(dst *ebiten.Image, width, height int) {
+	if t.Image == nil {
+		return
+	}
+
+	bounds := t.Image.Bounds()
+	if bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return
+	}
+
+	area := dst.SubImage(image.Rect(0, 0, width, height)).(*ebiten.Image)
+	for y := 0; y < height; y += bounds.Dy() {
+		for x := 0; x < width; x += bounds.Dx() {
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Translate(float64(x), float64(y))
+			area.DrawImage(t.Image, op)
+		}
+	}
+}
+
+// NineSlice splits an image into a grid of nine by Insets, in the image's
+// pixels, and stretches it over the box. The corners keep their size, the
+// edges stretch along their side, and the middle stretches both ways. A box
+// smaller than the corners squeezes them to fit.
+type NineSlice struct {
+	Image  *ebiten.Image
+	Insets config.Padding
+}
+
+func (n NineSlice) Fill(dst *ebiten.Image, width, height int) {
+	if n.Image == nil {
+		return
+	}
+
+	bounds := n.Image.Bounds()
+	columns := slices3(bounds.Min.X, bounds.Dx(), n.Insets.Left, n.Insets.Right)
+	rows := slices3(bounds.Min.Y, bounds.Dy(), n.Insets.Top, n.Insets.Bottom)
+	targetColumns := targets(width, n.Insets.Left, n.Insets.Right)
+	targetRows := targets(height, n.Insets.Top, n.Insets.Bottom)
+
+	for row := range 3 {
+		for column := range 3 {
+			source := image.Rect(columns[column][0], rows[row][0], columns[column][1], rows[row][1])
+			target := [2]float64{targetColumns[column][1] - targetColumns[column][0], targetRows[row][1] - targetRows[row][0]}
+			if source.Dx() <= 0 || source.Dy() <= 0 || target[0] <= 0 || target[1] <= 0 {
+				continue
+			}
+
+			op := &ebiten.DrawImageOptions{}
+			op.GeoM.Scale(target[0]/float64(source.Dx()), target[1]/float64(source.Dy()))
+			op.GeoM.Translate(targetColumns[column][0], targetRows[row][0])
+			dst.DrawImage(n.Image.SubImage(source).(*ebiten.Image), op)
+		}
+	}
+}
+
+// slices3 splits a length of the source image into start, middle and end
+// pieces, as [from, to) pairs.
+func slices3(origin, length, start, end int) [3][2]int {
+	start, end = min(max(start, 0), length), min(max(end, 0), length)
+	end = min(end, length-start)
+	return [3][2]int{
+		{origin, origin + start},
+		{origin + start, origin + length - end},
+		{origin + length - end, origin + length},
+	}
+}
+
+// targets is slices3 for the box, squeezing the ends when they don't fit.
+func targets(length, start, end int) [3][2]float64 {
+	l, s, e := float64(length), float64(max(start, 0)), float64(max(end, 0))
+	if s+e > l && s+e > 0 {
+		scale := l / (s + e)
+		s, e = s*scale, e*scale
+	}
+	return [3][2]float64{{0, s}, {s, l - e}, {l - e, l}}
+}
@@ -0,0 +1,78 @@
+package paint
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlices3(t *testing.T) {
+	tests := []struct {
+		name                       string
+		origin, length, start, end int
+		expected                   [3][2]int
+	}{
+		{"inside the image", 10, 30, 5, 8, [3][2]int{{10, 15}, {15, 32}, {32, 40}}},
+		{"no insets", 0, 30, 0, 0, [3][2]int{{0, 0}, {0, 30}, {30, 30}}},
+		{"negative insets", 0, 30, -2, -3, [3][2]int{{0, 0}, {0, 30}, {30, 30}}},
+		{"ends that overlap", 0, 10, 8, 8, [3][2]int{{0, 8}, {8, 8}, {8, 10}}},
+		{"a start past the end of the image", 0, 10, 15, 4, [3][2]int{{0, 10}, {10, 10}, {10, 10}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, slices3(tt.origin, tt.length, tt.start, tt.end))
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	tests := []struct {
+		name               string
+		length, start, end int
+		expected           [3][2]float64
+	}{
+		{"the ends fit", 100, 10, 20, [3][2]float64{{0, 10}, {10, 80}, {80, 100}}},
+		{"the ends fit exactly", 30, 10, 20, [3][2]float64{{0, 10}, {10, 10}, {10, 30}}},
+		{"the ends are squeezed in proportion", 30, 20, 40, [3][2]float64{{0, 10}, {10, 10}, {10, 30}}},
+		{"nothing to squeeze into", 0, 5, 5, [3][2]float64{{0, 0}, {0, 0}, {0, 0}}},
+		{"negative insets", 50, -5, 10, [3][2]float64{{0, 0}, {0, 40}, {40, 50}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, targets(tt.length, tt.start, tt.end))
+		})
+	}
+}
+
+func TestBlend(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+	black := color.RGBA{A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	tests := []struct {
+		name     string
+		stops    []Stop
+		t        float64
+		expected color.RGBA
+	}{
+		{"before the first stop", []Stop{{0.2, red}, {0.8, blue}}, 0, red},
+		{"after the last stop", []Stop{{0.2, red}, {0.8, blue}}, 1, blue},
+		{"halfway", []Stop{{0, red}, {1, blue}}, 0.5, color.RGBA{R: 128, B: 128, A: 255}},
+		{"between the right pair of stops", []Stop{{0, black}, {0.5, white}, {1, black}}, 0.75, color.RGBA{R: 128, G: 128, B: 128, A: 255}},
+		{"on a stop", []Stop{{0, black}, {0.5, white}, {1, black}}, 0.5, white},
+		{"premultiplied into transparent", []Stop{{0, red}, {1, color.RGBA{}}}, 0.5, color.RGBA{R: 128, A: 128}},
+		{"a single stop", []Stop{{0.5, red}}, 0.25, red},
+		{"a nil color at the ends", []Stop{{0, nil}, {1, red}}, 0, color.RGBA{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, g, b, a := blend(tt.stops, tt.t)
+			assert.Equal(t, tt.expected, color.RGBA{R: r, G: g, B: b, A: a})
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package paint
+
+import (
+	"image/color"
+
+	"github.com/jhuggett/thingamabob/config"
+)
+
+// Corners holds a radius for each corner of a box.
+type Corners struct {
+	TopLeft     float64
+	TopRight    float64
+	BottomRight float64
+	BottomLeft  float64
+}
+
+// Radius rounds every corner by the same amount.
+func Radius(radius float64) Corners {
+	return Corners{TopLeft: radius, TopRight: radius, BottomRight: radius, BottomLeft: radius}
+}
+
+// Edge is one side of a border. An edge with a width and no color is
+// transparent: nothing is painted there, but it still takes up its width.
+type Edge struct {
+	Width float64
+	Color color.Color
+}
+
+type Border struct {
+	Top    Edge
+	Right  Edge
+	Bottom Edge
+	Left   Edge
+}
+
+// UniformBorder is a border with the same width and color on every side.
+func UniformBorder(width float64, color color.Color) Border {
+	edge := Edge{Width: width, Color: color}
+	return Border{Top: edge, Right: edge, Bottom: edge, Left: edge}
+}
+
+// BorderFrom converts a config.Border, which has one color for every side.
+func BorderFrom(border config.Border) Border {
+	return Border{
+		Top:    Edge{Width: float64(border.Top), Color: border.Color},
+		Right:  Edge{Width: float64(border.Right), Color: border.Color},
+		Bottom: Edge{Width: float64(border.Bottom), Color: border.Color},
+		Left:   Edge{Width: float64(border.Left), Color: border.Color},
+	}
+}
+
+func (b Border) Exists() bool {
+	for _, edge := range b.edges() {
+		if edge.visible() {
+			return true
+		}
+	}
+	return false
+}
+
+// edges are in the order top, right, bottom, left.
+func (b Border) edges() [4]Edge {
+	return [4]Edge{b.Top, b.Right, b.Bottom, b.Left}
+}
+
+func (e Edge) visible() bool {
+	return e.Width > 0 && e.Color != nil
+}
+
+// uniform is true when every edge with a width has the same color, so the
+// border can be filled in one go. Transparent edges have to be left out of the
+// fill, so a border with any isn't uniform.
+func (b Border) uniform() bool {
+	var first color.Color
+	for _, edge := range b.edges() {
+		if edge.Width <= 0 {
+			continue
+		}
+		if edge.Color == nil {
+			return false
+		}
+		if first == nil {
+			first = edge.Color
+			continue
+		}
+		if !sameColor(first, edge.Color) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameColor(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
+
+// Style describes how a box's background is painted: filled with Color and
+// then Source, rounded at the corners, with a border on top. The border is
+// painted inside the box.
+type Style struct {
+	Color color.Color
+	// A gradient or image painted over Color, e.g. a LinearGradient or a
+	// NineSlice.
+	Source Source
+	Border Border
+	Radius Corners
+
+	// Edges are anti-aliased unless Aliased is set, e.g. for pixel art.
+	Aliased bool
+}
+
+// IsZero is true when the style doesn't paint anything.
+func (s Style) IsZero() bool {
+	return s.Color == nil && s.Source == nil && !s.Border.Exists()
+}
@@ -0,0 +1,52 @@
+package paint
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBorder(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	blue := color.RGBA{B: 255, A: 255}
+
+	tests := []struct {
+		name    string
+		border  Border
+		exists  bool
+		uniform bool
+	}{
+		{"none", Border{}, false, true},
+		{"uniform", UniformBorder(2, red), true, true},
+		{"the same color on some sides", Border{Top: Edge{Width: 2, Color: red}, Left: Edge{Width: 4, Color: color.NRGBA{R: 255, A: 255}}}, true, true},
+		{"a color on each side", Border{Top: Edge{Width: 2, Color: red}, Right: Edge{Width: 2, Color: blue}, Bottom: Edge{Width: 2, Color: red}, Left: Edge{Width: 2, Color: blue}}, true, false},
+		{"a transparent side", Border{Top: Edge{Width: 2, Color: red}, Right: Edge{Width: 6}, Bottom: Edge{Width: 2, Color: red}, Left: Edge{Width: 2, Color: red}}, true, false},
+		{"a color without a width", Border{Top: Edge{Width: 2, Color: red}, Right: Edge{Color: blue}}, true, true},
+		{"only transparent sides", Border{Top: Edge{Width: 2}, Bottom: Edge{Width: 2}}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exists, tt.border.Exists())
+			assert.Equal(t, tt.uniform, tt.border.uniform())
+		})
+	}
+}
+
+func TestInnerEdge(t *testing.T) {
+	outer := rect{width: 100, height: 50}
+	border := Border{
+		Top:    Edge{Width: 2, Color: color.Black},
+		Right:  Edge{Width: 6},
+		Bottom: Edge{Width: 4, Color: color.White},
+		Left:   Edge{Width: 8, Color: color.Black},
+	}
+
+	inner, innerRadii := innerEdge(outer, radii{{10, 10}, {10, 10}, {3, 3}, {10, 10}}, border)
+	assert.Equal(t, rect{x: 8, y: 2, width: 86, height: 44}, inner, "transparent sides still take up their width")
+	assert.Equal(t, radii{{2, 8}, {4, 8}, {0, 0}, {2, 6}}, innerRadii)
+
+	inner, _ = innerEdge(rect{width: 10, height: 4}, radii{}, border)
+	assert.Equal(t, rect{x: 8, y: 2}, inner, "never negative")
+}
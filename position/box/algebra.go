@@ -0,0 +1,144 @@
+package box
+
+import (
+	"math"
+
+	"github.com/jhuggett/thingamabob/config"
+)
+
+// Shrinks the box to the area it shares with other. If they don't overlap the
+// box ends up with zero width and/or height, positioned where they meet.
+func (b *Box) Intersect(other *Box) *Box {
+	f, o := b.Frame(), other.Frame()
+	left := max(f.X, o.X)
+	top := max(f.Y, o.Y)
+	right := min(f.X+f.Width, o.X+o.Width)
+	bottom := min(f.Y+f.Height, o.Y+o.Height)
+
+	b.setFrame(Frame{
+		X:      left,
+		Y:      top,
+		Width:  max(right-left, 0),
+		Height: max(bottom-top, 0),
+	})
+	return b
+}
+
+// Grows the box to the smallest box that contains both it and other.
+func (b *Box) Union(other *Box) *Box {
+	b.setFrame(union(b.Frame(), other.Frame()))
+	return b
+}
+
+func union(f, o Frame) Frame {
+	left := min(f.X, o.X)
+	top := min(f.Y, o.Y)
+	right := max(f.X+f.Width, o.X+o.Width)
+	bottom := max(f.Y+f.Height, o.Y+o.Height)
+
+	return Frame{X: left, Y: top, Width: right - left, Height: bottom - top}
+}
+
+// Moves each edge inwards by the matching padding. The box never ends up with
+// a negative width or height.
+func (b *Box) Inset(padding config.Padding) *Box {
+	b.setFrame(b.Frame().Inset(padding))
+	return b
+}
+
+// Moves each edge outwards by the matching padding.
+func (b *Box) Outset(padding config.Padding) *Box {
+	b.setFrame(b.Frame().Outset(padding))
+	return b
+}
+
+// If the two boxes share any area. Boxes that only touch edges, or have no
+// area of their own, don't overlap.
+func (b *Box) Overlaps(other *Box) bool {
+	f, o := b.Frame(), other.Frame()
+	if f.Width <= 0 || f.Height <= 0 || o.Width <= 0 || o.Height <= 0 {
+		return false
+	}
+
+	return f.X < o.X+o.Width && o.X < f.X+f.Width &&
+		f.Y < o.Y+o.Height && o.Y < f.Y+f.Height
+}
+
+// Splits the box into n columns laid out left to right with gap between them.
+// Leftover pixels go to the leftmost columns so the columns cover the box
+// exactly. The returned boxes are snapshots, copy them inside a Computed step
+// to keep them up to date.
+func (b *Box) SplitHorizontally(n int, gap int) []*Box {
+	sizes := splitLength(b.Width(), n, gap)
+
+	columns := make([]*Box, len(sizes))
+	x := b.X()
+	for i, size := range sizes {
+		columns[i] = New(Config{X: x, Y: b.Y(), Width: size, Height: b.Height()})
+		x += size + gap
+	}
+	return columns
+}
+
+// Splits the box into n rows laid out top to bottom with gap between them.
+// See SplitHorizontally.
+func (b *Box) SplitVertically(n int, gap int) []*Box {
+	sizes := splitLength(b.Height(), n, gap)
+
+	rows := make([]*Box, len(sizes))
+	y := b.Y()
+	for i, size := range sizes {
+		rows[i] = New(Config{X: b.X(), Y: y, Width: b.Width(), Height: size})
+		y += size + gap
+	}
+	return rows
+}
+
+// Splits the box into a left and a right part, giving fraction (0 to 1) of its
+// width to the left part. Use SplitAlong to split it another way.
+func (b *Box) SplitAt(fraction float64) (*Box, *Box) {
+	return b.SplitAlong(fraction, config.LeftToRight)
+}
+
+// Splits the box in two along the flow, giving fraction (0 to 1) of it to the
+// first box. The first box is the part the flow starts in, e.g. the left part
+// with config.LeftToRight and the bottom part with config.BottomToTop.
+func (b *Box) SplitAlong(fraction float64, flow config.Flow) (*Box, *Box) {
+	fraction = math.Max(0, math.Min(1, fraction))
+	if flow.IsReversed() {
+		fraction = 1 - fraction
+	}
+
+	var first, second *Box
+	if flow.IsHorizontal() {
+		width := int(math.Round(float64(b.Width()) * fraction))
+		first = New(Config{X: b.X(), Y: b.Y(), Width: width, Height: b.Height()})
+		second = New(Config{X: b.X() + width, Y: b.Y(), Width: b.Width() - width, Height: b.Height()})
+	} else {
+		height := int(math.Round(float64(b.Height()) * fraction))
+		first = New(Config{X: b.X(), Y: b.Y(), Width: b.Width(), Height: height})
+		second = New(Config{X: b.X(), Y: b.Y() + height, Width: b.Width(), Height: b.Height() - height})
+	}
+
+	if flow.IsReversed() {
+		return second, first
+	}
+	return first, second
+}
+
+// splitLength divides length into n parts separated by gap.
+func splitLength(length int, n int, gap int) []int {
+	if n <= 0 {
+		return nil
+	}
+
+	available := max(length-gap*(n-1), 0)
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = available / n
+		if i < available%n {
+			sizes[i]++
+		}
+	}
+	return sizes
+}
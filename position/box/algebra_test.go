@@ -0,0 +1,199 @@
+package box
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func rectsOf(boxes []*Box) []Rect {
+	rects := make([]Rect, len(boxes))
+	for i, b := range boxes {
+		rects[i] = b.Rect()
+	}
+	return rects
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Rect
+		expected Rect
+	}{
+		{"overlapping", Rect{0, 0, 100, 100}, Rect{50, 25, 100, 100}, Rect{50, 25, 50, 75}},
+		{"contained", Rect{0, 0, 100, 100}, Rect{10, 10, 20, 20}, Rect{10, 10, 20, 20}},
+		{"containing", Rect{10, 10, 20, 20}, Rect{0, 0, 100, 100}, Rect{10, 10, 20, 20}},
+		{"identical", Rect{5, 5, 10, 10}, Rect{5, 5, 10, 10}, Rect{5, 5, 10, 10}},
+		{"touching edges", Rect{0, 0, 10, 10}, Rect{10, 0, 10, 10}, Rect{10, 0, 0, 10}},
+		{"disjoint", Rect{0, 0, 10, 10}, Rect{50, 60, 10, 10}, Rect{50, 60, 0, 0}},
+		{"negative origin", Rect{-20, -20, 30, 30}, Rect{0, 0, 30, 30}, Rect{0, 0, 10, 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(Config(tt.a))
+			assert.Equal(t, tt.expected, a.Intersect(New(Config(tt.b))).Rect())
+		})
+	}
+}
+
+func TestUnion(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Rect
+		expected Rect
+	}{
+		{"overlapping", Rect{0, 0, 100, 100}, Rect{50, 25, 100, 100}, Rect{0, 0, 150, 125}},
+		{"contained", Rect{0, 0, 100, 100}, Rect{10, 10, 20, 20}, Rect{0, 0, 100, 100}},
+		{"disjoint", Rect{0, 0, 10, 10}, Rect{50, 60, 10, 10}, Rect{0, 0, 60, 70}},
+		{"other before", Rect{50, 50, 10, 10}, Rect{0, 0, 10, 10}, Rect{0, 0, 60, 60}},
+		{"negative origin", Rect{-20, -20, 10, 10}, Rect{0, 0, 10, 10}, Rect{-20, -20, 30, 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := New(Config(tt.a))
+			assert.Equal(t, tt.expected, a.Union(New(Config(tt.b))).Rect())
+		})
+	}
+}
+
+func TestInsetAndOutset(t *testing.T) {
+	tests := []struct {
+		name    string
+		rect    Rect
+		padding config.Padding
+		inset   Rect
+		outset  Rect
+	}{
+		{"none", Rect{0, 0, 100, 50}, config.Padding{}, Rect{0, 0, 100, 50}, Rect{0, 0, 100, 50}},
+		{"equal", Rect{0, 0, 100, 50}, config.EqualPadding(10), Rect{10, 10, 80, 30}, Rect{-10, -10, 120, 70}},
+		{"symmetric", Rect{10, 10, 100, 50}, config.SymmetricPadding(5, 20), Rect{30, 15, 60, 40}, Rect{-10, 5, 140, 60}},
+		{"uneven", Rect{0, 0, 100, 100}, config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4}, Rect{4, 1, 94, 96}, Rect{-4, -1, 106, 104}},
+		{"larger than box", Rect{0, 0, 10, 10}, config.EqualPadding(20), Rect{20, 20, 0, 0}, Rect{-20, -20, 50, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.inset, New(Config(tt.rect)).Inset(tt.padding).Rect())
+			assert.Equal(t, tt.outset, New(Config(tt.rect)).Outset(tt.padding).Rect())
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     Rect
+		expected bool
+	}{
+		{"overlapping", Rect{0, 0, 100, 100}, Rect{50, 50, 100, 100}, true},
+		{"contained", Rect{0, 0, 100, 100}, Rect{10, 10, 20, 20}, true},
+		{"touching edges", Rect{0, 0, 10, 10}, Rect{10, 0, 10, 10}, false},
+		{"touching corners", Rect{0, 0, 10, 10}, Rect{10, 10, 10, 10}, false},
+		{"disjoint", Rect{0, 0, 10, 10}, Rect{50, 50, 10, 10}, false},
+		{"degenerate", Rect{5, 5, 0, 0}, Rect{0, 0, 10, 10}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := New(Config(tt.a)), New(Config(tt.b))
+			assert.Equal(t, tt.expected, a.Overlaps(b))
+			assert.Equal(t, tt.expected, b.Overlaps(a), "overlap should be symmetric")
+		})
+	}
+}
+
+func TestSplitHorizontally(t *testing.T) {
+	tests := []struct {
+		name     string
+		rect     Rect
+		n, gap   int
+		expected []Rect
+	}{
+		{"even", Rect{0, 0, 90, 10}, 3, 0, []Rect{{0, 0, 30, 10}, {30, 0, 30, 10}, {60, 0, 30, 10}}},
+		{"with gap", Rect{10, 5, 100, 10}, 3, 5, []Rect{{10, 5, 30, 10}, {45, 5, 30, 10}, {80, 5, 30, 10}}},
+		{"remainder", Rect{0, 0, 11, 10}, 3, 0, []Rect{{0, 0, 4, 10}, {4, 0, 4, 10}, {8, 0, 3, 10}}},
+		{"single", Rect{0, 0, 50, 10}, 1, 20, []Rect{{0, 0, 50, 10}}},
+		{"gaps wider than box", Rect{0, 0, 10, 10}, 3, 10, []Rect{{0, 0, 0, 10}, {10, 0, 0, 10}, {20, 0, 0, 10}}},
+		{"none", Rect{0, 0, 50, 10}, 0, 0, []Rect{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rectsOf(New(Config(tt.rect)).SplitHorizontally(tt.n, tt.gap)))
+		})
+	}
+}
+
+func TestSplitVertically(t *testing.T) {
+	tests := []struct {
+		name     string
+		rect     Rect
+		n, gap   int
+		expected []Rect
+	}{
+		{"even", Rect{0, 0, 10, 90}, 3, 0, []Rect{{0, 0, 10, 30}, {0, 30, 10, 30}, {0, 60, 10, 30}}},
+		{"with gap", Rect{5, 10, 10, 100}, 3, 5, []Rect{{5, 10, 10, 30}, {5, 45, 10, 30}, {5, 80, 10, 30}}},
+		{"remainder", Rect{0, 0, 10, 11}, 3, 0, []Rect{{0, 0, 10, 4}, {0, 4, 10, 4}, {0, 8, 10, 3}}},
+		{"none", Rect{0, 0, 10, 50}, -1, 0, []Rect{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rectsOf(New(Config(tt.rect)).SplitVertically(tt.n, tt.gap)))
+		})
+	}
+}
+
+func TestSplitAt(t *testing.T) {
+	first, second := New(Config{X: 10, Y: 5, Width: 100, Height: 50}).SplitAt(0.3)
+	assert.Equal(t, Rect{10, 5, 30, 50}, first.Rect())
+	assert.Equal(t, Rect{40, 5, 70, 50}, second.Rect())
+}
+
+func TestSplitAlong(t *testing.T) {
+	tests := []struct {
+		name          string
+		rect          Rect
+		fraction      float64
+		flow          config.Flow
+		first, second Rect
+	}{
+		{"half left to right", Rect{0, 0, 100, 50}, 0.5, config.LeftToRight, Rect{0, 0, 50, 50}, Rect{50, 0, 50, 50}},
+		{"third top to bottom", Rect{10, 10, 50, 90}, 1.0 / 3, config.TopToBottom, Rect{10, 10, 50, 30}, Rect{10, 40, 50, 60}},
+		{"rounds", Rect{0, 0, 5, 5}, 0.5, config.LeftToRight, Rect{0, 0, 3, 5}, Rect{3, 0, 2, 5}},
+		{"zero", Rect{0, 0, 100, 50}, 0, config.LeftToRight, Rect{0, 0, 0, 50}, Rect{0, 0, 100, 50}},
+		{"clamped above one", Rect{0, 0, 100, 50}, 2, config.TopToBottom, Rect{0, 0, 100, 50}, Rect{0, 50, 100, 0}},
+		{"clamped below zero", Rect{0, 0, 100, 50}, -1, config.TopToBottom, Rect{0, 0, 100, 0}, Rect{0, 0, 100, 50}},
+		{"quarter right to left", Rect{0, 0, 100, 50}, 0.25, config.RightToLeft, Rect{75, 0, 25, 50}, Rect{0, 0, 75, 50}},
+		{"quarter bottom to top", Rect{0, 0, 100, 40}, 0.25, config.BottomToTop, Rect{0, 30, 100, 10}, Rect{0, 0, 100, 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, second := New(Config(tt.rect)).SplitAlong(tt.fraction, tt.flow)
+			assert.Equal(t, tt.first, first.Rect())
+			assert.Equal(t, tt.second, second.Rect())
+		})
+	}
+}
+
+func TestAlgebraInsideComputed(t *testing.T) {
+	page := New(Config{Width: 1000, Height: 800})
+	navBar := New(Config{Width: 190})
+
+	contentPane := Computed(func(b *Box) {
+		_, content := Copy(page).SplitAt(float64(navBar.Width()) / float64(page.Width()))
+		b.Copy(content).Inset(config.EqualPadding(10))
+	})
+	assert.NoError(t, page.AddDependent(contentPane))
+
+	assert.Equal(t, Rect{200, 10, 790, 780}, contentPane.Rect())
+
+	page.SetDimensions(1190, 600)
+	page.Recalculate()
+
+	assert.Equal(t, Rect{200, 10, 980, 580}, contentPane.Rect())
+}
@@ -0,0 +1,57 @@
+package box
+
+import "slices"
+
+// transaction collects the boxes flagged or recalculated while a Batch is open.
+type transaction struct {
+	depth int
+	roots []*Box
+}
+
+var openTransaction transaction
+
+// Batch runs fn with flagging and recalculation deferred, then recalculates
+// everything that was touched in one pass once the outermost Batch returns.
+//
+// Inside fn a box that was flagged still recalculates itself when read, but
+// the boxes depending on it are not updated until the batch commits.
+func Batch(fn func()) {
+	openTransaction.depth++
+
+	completed := false
+	defer func() {
+		openTransaction.depth--
+		if openTransaction.depth > 0 {
+			return
+		}
+
+		roots := openTransaction.roots
+		openTransaction.roots = nil
+
+		if completed {
+			recalculate(roots)
+		}
+	}()
+
+	fn()
+	completed = true
+}
+
+// InBatch reports whether a Batch is currently open.
+func InBatch() bool {
+	return openTransaction.depth > 0
+}
+
+// deferToBatch records b for the pending commit. It reports false when no
+// batch is open and the caller should do the work right away.
+func (b *Box) deferToBatch() bool {
+	if !InBatch() {
+		return false
+	}
+
+	b.needsRecalculation = true
+	if !slices.Contains(openTransaction.roots, b) {
+		openTransaction.roots = append(openTransaction.roots, b)
+	}
+	return true
+}
@@ -3,41 +3,54 @@ package box
 import (
 	"log/slog"
 	"slices"
+
+	"github.com/jhuggett/thingamabob/config"
 )
 
 type Box struct {
-	x int
-	y int
+	x float64
+	y float64
 
-	width  int
-	height int
+	width  float64
+	height float64
 
-	calculationSteps   []func(*Box)
+	calculationSteps   []*Step
 	needsRecalculation bool
 
 	dependents []*Box
 
 	recalculationCount int
+
+	isCalculating bool
+	// Boxes whose steps read this box while it was only partially calculated.
+	staleReaders []*Box
+
+	observers []*changeObserver
+
+	limits Limits
+	margin config.Padding
+	// Boxes whose steps read the margin, to lay this box out.
+	marginReaders []*Box
 }
 
 func (b *Box) XY() (int, int) {
-	b.recalculateIfNeeded()
-	return b.x, b.y
+	r := b.Rect()
+	return r.X, r.Y
 }
 
 func (b *Box) ZeroOut() *Box {
-	b.x = 0
-	b.y = 0
-	b.width = 0
-	b.height = 0
+	b.setRect(Rect{})
 
 	return b
 }
 
 func (b *Box) Nuke() *Box {
+	b.limits = Limits{}
+	b.margin = config.Padding{}
+	b.marginReaders = nil
 	b.ZeroOut()
 	b.ClearDependents()
-	b.calculationSteps = []func(*Box){}
+	b.calculationSteps = []*Step{}
 	return b
 }
 
@@ -51,26 +64,30 @@ func (b *Box) IsADegenerate() bool {
 	return b.Width() == 0 && b.Height() == 0
 }
 
+// Flags the box and everything that depends on it, directly or indirectly.
+// Nothing is recalculated until a value is read.
 func (b *Box) FlagNeedsRecalculation() *Box {
-	b.needsRecalculation = true
-
-	for _, dependent := range b.dependents {
-		dependent.FlagNeedsRecalculation()
+	if b.deferToBatch() {
+		return b
 	}
 
+	flag(topologicalOrder([]*Box{b}))
+
 	return b
 }
 
+// Recalculates the box and everything that depends on it. Each box is
+// recalculated once, after all of the boxes it depends on.
 func (b *Box) Recalculate() *Box {
 	if b == nil {
 		return nil
 	}
 
-	b.needsRecalculation = true
-	b.recalculateIfNeeded()
-	for _, dependent := range b.dependents {
-		dependent.Recalculate()
+	if b.deferToBatch() {
+		return b
 	}
+
+	recalculate([]*Box{b})
 	return b
 }
 
@@ -78,11 +95,13 @@ func (b *Box) HasDependent(dependent *Box) bool {
 	return slices.Contains(b.dependents, dependent)
 }
 
-func (b *Box) AddDependent(dependent *Box) {
+// Registers dependent to be recalculated whenever b is. Returns a *CycleError
+// if b already depends on dependent, however indirectly.
+func (b *Box) AddDependent(dependent *Box) error {
 	if b == dependent {
 		// panic("A Box cannot depend on itself")
 		slog.Warn("A Box cannot depend on itself, ignoring dependency", "box", b)
-		return
+		return nil
 	}
 	if dependent == nil {
 		panic("Cannot add a nil Box as a dependent")
@@ -92,13 +111,12 @@ func (b *Box) AddDependent(dependent *Box) {
 			panic("A Box cannot depend on the same Box multiple times")
 		}
 	}
-	for _, existingDependent := range dependent.dependents {
-		if existingDependent == b {
-			panic("A Box cannot depend on a Box that already depends on it")
-		}
+	if err := b.cycleWith(dependent); err != nil {
+		return err
 	}
 
 	b.dependents = append(b.dependents, dependent)
+	return nil
 }
 
 func (b *Box) ClearDependents() {
@@ -123,36 +141,22 @@ type Config struct {
 
 func New(config Config) *Box {
 	return &Box{
-		x:                config.X,
-		y:                config.Y,
-		width:            config.Width,
-		height:           config.Height,
+		x:                float64(config.X),
+		y:                float64(config.Y),
+		width:            float64(config.Width),
+		height:           float64(config.Height),
 		dependents:       []*Box{},
-		calculationSteps: []func(*Box){},
+		calculationSteps: []*Step{},
 	}
 }
 
-func (b *Box) Computed(calculateFn func(*Box), dependents ...*Box) *Box {
-
-	// show we link the step with teh dpenedents so the dependents are remove the step is removed too?
-
-	if calculateFn == nil {
-		panic("calculateFn cannot be nil")
-	}
-
-	if b.calculationSteps == nil {
-		b.calculationSteps = []func(*Box){}
-	}
-
-	b.calculationSteps = append(b.calculationSteps, calculateFn)
-	for _, dependent := range dependents {
-		b.AddDependent(dependent)
-	}
-	b.FlagNeedsRecalculation()
-	return b
+// Adds a calculation step to the box, run every time the box is recalculated.
+// The returned step can be removed again with RemoveStep.
+func (b *Box) Computed(calculateFn func(*Box), dependents ...*Box) *Step {
+	return b.NamedComputed("", calculateFn, dependents...)
 }
 
-func (b *Box) CalculationSteps() []func(*Box) {
+func (b *Box) CalculationSteps() []*Step {
 	return b.calculationSteps
 }
 
@@ -161,137 +165,166 @@ func (b *Box) Dependents() []*Box {
 }
 
 func (b *Box) MoveBelow(other *Box) *Box {
-	b.y = other.Y() + other.Height()
+	b.SetY(other.Y() + other.Height())
 
 	return b
 }
 
 func (b *Box) MoveAbove(other *Box) *Box {
-	b.y = other.Y() - b.height
+	b.SetY(other.Y() - b.rect().Height)
 	return b
 }
 
 func (b *Box) MoveLeftOf(other *Box) *Box {
-	b.x = other.X() - b.width
+	b.SetX(other.X() - b.rect().Width)
 
 	return b
 }
 
 func (b *Box) MoveRightOf(other *Box) *Box {
-	b.x = other.X() + other.Width()
+	b.SetX(other.X() + other.Width())
 
 	return b
 }
 
 func (b *Box) CopyPositionOf(other *Box) *Box {
-	b.x = other.X()
-	b.y = other.Y()
+	o := other.Frame()
+	b.SetFrameOrigin(o.X, o.Y)
 
 	return b
 }
 
 func (b *Box) CopyDimensionsOf(other *Box) *Box {
-	b.width = other.Width()
-	b.height = other.Height()
+	f, o := b.frame(), other.Frame()
+	f.Width, f.Height = o.Width, o.Height
+	b.setFrame(f)
 
 	return b
 }
 
 func (b *Box) Copy(other *Box) *Box {
-	b.x = other.X()
-	b.y = other.Y()
-	b.width = other.Width()
-	b.height = other.Height()
+	b.setFrame(other.Frame())
 
 	return b
 }
 
 func (b *Box) SetPosition(x, y int) {
-	b.x = x
-	b.y = y
+	b.SetOrigin(x, y)
 }
 
 func (b *Box) Contains(other *Box) bool {
-	return b.x <= other.X() && b.y <= other.Y() && (b.x+b.width) >= (other.X()+other.Width()) && (b.y+b.height) >= (other.Y()+other.Height())
+	f, o := b.frame(), other.Frame()
+	return f.X <= o.X && f.Y <= o.Y && (f.X+f.Width) >= (o.X+o.Width) && (f.Y+f.Height) >= (o.Y+o.Height)
 }
 
 func (b *Box) recalculateIfNeeded() {
+	if b.isCalculating {
+		// Another box is reading us before our steps have finished, so it will
+		// need another pass once they have.
+		if reader := currentlyCalculating(); reader != nil && reader != b && !slices.Contains(b.staleReaders, reader) {
+			b.staleReaders = append(b.staleReaders, reader)
+		}
+		return
+	}
+
 	if b.needsRecalculation && len(b.calculationSteps) > 0 {
 
 		b.recalculationCount++
 		b.needsRecalculation = false
-		b.ZeroOut()
-		for _, step := range b.calculationSteps {
-			step(b)
-		}
+
+		old := b.rect()
+		b.runCalculationSteps()
+		b.notifyIfChanged(old)
 	}
 
 }
 
+func (b *Box) runCalculationSteps() {
+	b.isCalculating = true
+	calculating = append(calculating, b)
+
+	defer func() {
+		calculating = calculating[:len(calculating)-1]
+		b.isCalculating = false
+
+		staleReaders := b.staleReaders
+		b.staleReaders = nil
+		flag(topologicalOrder(staleReaders))
+	}()
+
+	b.ZeroOut()
+	for _, step := range b.calculationSteps {
+		step.calculate(b)
+	}
+	b.setFrame(b.limited(b.frame()))
+}
+
 func (b *Box) X() int {
-	b.recalculateIfNeeded()
-	return b.x
+	return b.Rect().X
 }
 
 func (b *Box) Y() int {
-	b.recalculateIfNeeded()
-	return b.y
+	return b.Rect().Y
 }
 
 func (b *Box) Width() int {
-	b.recalculateIfNeeded()
-	return b.width
+	return b.Rect().Width
 }
 
 func (b *Box) Height() int {
-	b.recalculateIfNeeded()
-	return b.height
+	return b.Rect().Height
 }
 
 func (b *Box) SetWidth(width int) *Box {
-	b.width = width
+	f := b.frame()
+	f.Width = float64(width)
+	b.setFrame(f)
 	return b
 }
 
 func (b *Box) SetHeight(height int) *Box {
-	b.height = height
+	f := b.frame()
+	f.Height = float64(height)
+	b.setFrame(f)
 	return b
 }
 
 func (b *Box) SetDimensions(width, height int) *Box {
-	b.width, b.height = width, height
+	f := b.frame()
+	f.Width, f.Height = float64(width), float64(height)
+	b.setFrame(f)
 	return b
 }
 
 func (b *Box) SetX(x int) *Box {
-	b.x = x
+	f := b.frame()
+	f.X = float64(x)
+	b.setFrame(f)
 	return b
 }
 
 func (b *Box) SetY(y int) *Box {
-	b.y = y
+	f := b.frame()
+	f.Y = float64(y)
+	b.setFrame(f)
 	return b
 }
 
 func (b *Box) SetOrigin(x, y int) *Box {
-	b.x, b.y = x, y
+	b.SetFrameOrigin(float64(x), float64(y))
 	return b
 }
 
 func (b *Box) CenterVerticallyWithin(other *Box) *Box {
-	otherCenterY := other.Y() + other.Height()/2
-	y := otherCenterY - b.height/2
-
-	b.SetOrigin(b.x, y)
+	o := other.Frame()
+	b.SetFrameOrigin(b.x, o.Y+Half(o.Height)-Half(b.height))
 
 	return b
 }
 
 func (b *Box) CenterHorizontallyWithin(other *Box) *Box {
-	otherCenterX := other.X() + other.Width()/2
-	x := otherCenterX - b.width/2
-
-	b.SetOrigin(x, b.y)
+	o := other.Frame()
+	b.SetFrameOrigin(o.X+Half(o.Width)-Half(b.width), b.y)
 
 	return b
 }
@@ -342,42 +375,23 @@ func Zeroed() *Box {
 }
 
 func Computed(calculateFn func(*Box)) *Box {
-	return Zeroed().Computed(calculateFn)
+	b := Zeroed()
+	b.Computed(calculateFn)
+	return b
 }
 
+// Bounding returns the smallest box containing every box and its margin.
 func Bounding(boxes []*Box) *Box {
 	boundingBox := Zeroed()
-
-	// Find the minimum x and y coordinates
-	for i, box := range boxes {
-		if i == 0 {
-			boundingBox.SetX(box.X())
-			boundingBox.SetY(box.Y())
-			boundingBox.SetWidth(box.Width())
-			boundingBox.SetHeight(box.Height())
-			continue
-		}
-
-		if box.X() < boundingBox.X() {
-			boundingBox.SetX(box.X())
-		}
-		if box.Y() < boundingBox.Y() {
-			boundingBox.SetY(box.Y())
-		}
+	if len(boxes) == 0 {
+		return boundingBox
 	}
 
-	for _, box := range boxes {
-		boundingBoxWidth, boundingBoxHeight := boundingBox.Width(), boundingBox.Height()
-		childX, childY, childWidth, childHeight := box.X(), box.Y(), box.Width(), box.Height()
-
-		if childX+childWidth > boundingBoxWidth+boundingBox.X() {
-			boundingBox.SetWidth(childX + childWidth - boundingBox.X())
-		}
-
-		if childY+childHeight > boundingBoxHeight+boundingBox.Y() {
-			boundingBox.SetHeight(childY + childHeight - boundingBox.Y())
-		}
+	frame := boxes[0].MarginFrame()
+	for _, box := range boxes[1:] {
+		frame = union(frame, box.MarginFrame())
 	}
+	boundingBox.setFrame(frame)
 
 	return boundingBox
 }
@@ -3,6 +3,7 @@ package box
 import (
 	"testing"
 
+	"github.com/jhuggett/thingamabob/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -61,3 +62,297 @@ func TestLayeredComputed(t *testing.T) {
 	assert.Equal(t, 5, boxA.Y(), "Box A Y should be 10")
 
 }
+
+func TestDiamondRecalculatesEachBoxOnce(t *testing.T) {
+	top := New(Config{Width: 100, Height: 50})
+
+	left := Computed(func(b *Box) {
+		b.CopyDimensionsOf(top)
+	})
+	right := Computed(func(b *Box) {
+		b.CopyDimensionsOf(top).MoveRight(top.Width())
+	})
+	bottom := Computed(func(b *Box) {
+		b.SetX(right.X()).SetWidth(left.Width())
+	})
+
+	assert.NoError(t, top.AddDependent(left))
+	assert.NoError(t, top.AddDependent(right))
+	assert.NoError(t, left.AddDependent(bottom))
+	assert.NoError(t, right.AddDependent(bottom))
+
+	bottom.recalculationCount = 0
+
+	top.SetWidth(200)
+	top.Recalculate()
+
+	assert.Equal(t, 1, bottom.recalculationCount, "bottom should be recalculated once per pass")
+	assert.Equal(t, 200, bottom.X())
+	assert.Equal(t, 200, bottom.Width())
+}
+
+func TestAddDependentDetectsLongCycles(t *testing.T) {
+	a, b, c := Zeroed(), Zeroed(), Zeroed()
+
+	assert.NoError(t, a.AddDependent(b))
+	assert.NoError(t, b.AddDependent(c))
+
+	err := c.AddDependent(a)
+
+	var cycleErr *CycleError
+	assert.ErrorAs(t, err, &cycleErr)
+	assert.Equal(t, []*Box{c, a, b, c}, cycleErr.Path)
+	assert.False(t, c.HasDependent(a), "the cyclic dependency should not be added")
+}
+
+func TestReadingAPartiallyCalculatedBoxIsCorrected(t *testing.T) {
+	// Like a stack: the parent sizes itself from its child while the child
+	// positions itself from the parent.
+	parent := New(Config{})
+	child := New(Config{})
+
+	parent.Computed(func(b *Box) {
+		b.SetOrigin(30, 40)
+		b.SetWidth(child.Width() + 20)
+	}, child)
+
+	child.Computed(func(b *Box) {
+		b.SetOrigin(parent.X()+10, parent.Y()+10).SetWidth(50)
+	})
+
+	parent.Recalculate()
+
+	assert.Equal(t, 70, parent.Width())
+	assert.Equal(t, 40, child.X())
+	assert.Equal(t, 50, child.Y())
+}
+
+func TestBatchRecalculatesOnceAtCommit(t *testing.T) {
+	panel := New(Config{Width: 100, Height: 100})
+
+	children := make([]*Box, 10)
+	for i := range children {
+		children[i] = New(Config{})
+		assert.NoError(t, panel.AddDependent(children[i]))
+	}
+
+	for i, child := range children {
+		child.Computed(func(b *Box) {
+			b.CopyDimensionsOf(panel).MoveDown(i * 10)
+		})
+		child.Recalculate()
+		child.recalculationCount = 0
+	}
+
+	Batch(func() {
+		assert.True(t, InBatch())
+
+		panel.Computed(func(b *Box) {
+			b.SetDimensions(300, 20)
+		})
+		panel.Recalculate()
+
+		Batch(func() {
+			panel.Computed(func(b *Box) {
+				b.IncreaseWidth(50)
+			})
+			panel.Recalculate()
+		})
+
+		assert.Equal(t, 0, children[3].recalculationCount, "nothing should be recalculated before the batch commits")
+	})
+
+	assert.False(t, InBatch())
+	for i, child := range children {
+		assert.Equal(t, 1, child.recalculationCount)
+		assert.Equal(t, 350, child.Width())
+		assert.Equal(t, i*10, child.Y())
+	}
+}
+
+func TestRemoveStep(t *testing.T) {
+	b := New(Config{})
+	dependent := Computed(func(d *Box) {
+		d.SetWidth(10)
+	})
+
+	b.Computed(func(b *Box) {
+		b.SetWidth(100)
+	})
+	grow := b.Computed(func(b *Box) {
+		b.IncreaseWidth(50)
+	}, dependent)
+
+	assert.Equal(t, 150, b.Width())
+	assert.True(t, b.HasDependent(dependent))
+
+	b.RemoveStep(grow)
+
+	assert.Equal(t, 100, b.Width())
+	assert.Len(t, b.CalculationSteps(), 1)
+	assert.False(t, b.HasDependent(dependent), "dependents registered with the step should be detached")
+	assert.Nil(t, grow.Box())
+}
+
+func TestNamedComputedReplacesInPlace(t *testing.T) {
+	b := New(Config{})
+	first, second := Zeroed(), Zeroed()
+
+	b.NamedComputed("size", func(b *Box) {
+		b.SetWidth(100)
+	}, first)
+	b.Computed(func(b *Box) {
+		b.IncreaseWidth(1)
+	})
+	b.NamedComputed("size", func(b *Box) {
+		b.SetWidth(200)
+	}, second)
+
+	assert.Len(t, b.CalculationSteps(), 2)
+	assert.Equal(t, "size", b.CalculationSteps()[0].Name())
+	assert.Equal(t, 201, b.Width(), "the replacement should keep the original step's position")
+	assert.False(t, b.HasDependent(first))
+	assert.True(t, b.HasDependent(second))
+
+	b.RemoveNamedStep("size")
+	assert.Nil(t, b.Step("size"))
+	assert.False(t, b.HasDependent(second))
+}
+
+func TestNamedComputedLeavesTheBoxAloneOnACycle(t *testing.T) {
+	a, b, c := Zeroed(), Zeroed(), Zeroed()
+	assert.NoError(t, a.AddDependent(b))
+	cycle := (&CycleError{Path: []*Box{b, a, b}}).Error()
+
+	assert.PanicsWithError(t, cycle, func() {
+		b.NamedComputed("position", func(b *Box) {}, c, a)
+	})
+	assert.Empty(t, b.CalculationSteps())
+	assert.False(t, b.HasDependent(c), "dependents before the cyclic one shouldn't be added")
+
+	size := b.NamedComputed("size", func(b *Box) {}, c)
+	assert.PanicsWithError(t, cycle, func() {
+		b.NamedComputed("size", func(b *Box) {}, a)
+	})
+	assert.Same(t, size, b.Step("size"), "the step being replaced should be kept")
+	assert.True(t, b.HasDependent(c))
+}
+
+func TestOnChange(t *testing.T) {
+	parent := New(Config{Width: 100, Height: 100})
+	child := New(Config{})
+	assert.NoError(t, parent.AddDependent(child))
+
+	child.Computed(func(b *Box) {
+		b.CopyDimensionsOf(parent).DecreaseWidth(10).MoveRight(5)
+	})
+	child.Recalculate()
+
+	changes := []Rect{}
+	unregister := child.OnChange(func(old, new Rect) {
+		changes = append(changes, new)
+	})
+
+	parent.Recalculate()
+	assert.Empty(t, changes, "recalculating to the same rect should not notify")
+
+	parent.SetWidth(200)
+	parent.Recalculate()
+	assert.Equal(t, []Rect{{X: 5, Width: 190, Height: 100}}, changes, "steps should be reported once, after they have all run")
+
+	child.SetY(20)
+	assert.Len(t, changes, 2)
+
+	unregister()
+	child.SetY(30)
+	assert.Len(t, changes, 2)
+}
+
+func TestCenteringSnapsUnlessSubpixel(t *testing.T) {
+	outer := New(Config{Width: 101, Height: 11})
+	inner := New(Config{Width: 10, Height: 10})
+
+	inner.CenterWithin(outer)
+	assert.Equal(t, Frame{X: 45, Y: 0, Width: 10, Height: 10}, inner.Frame())
+
+	inner.SetFrame(Frame{X: 1.4, Y: 2.6, Width: 10, Height: 10})
+	assert.Equal(t, Frame{X: 1, Y: 3, Width: 10, Height: 10}, inner.Frame(), "without subpixel layout writes are snapped")
+
+	SetSubpixel(true)
+	defer SetSubpixel(false)
+
+	inner.CenterWithin(outer)
+	assert.Equal(t, Frame{X: 45.5, Y: 0.5, Width: 10, Height: 10}, inner.Frame())
+	assert.Equal(t, Rect{X: 46, Y: 1, Width: 10, Height: 10}, inner.Rect())
+	assert.Equal(t, 46, inner.X())
+
+	// Snapping the edges keeps boxes that touch touching.
+	left := New(Config{}).SetFrame(Frame{X: 0.4, Width: 10.2, Height: 1})
+	right := New(Config{}).SetFrame(Frame{X: 10.6, Width: 10.2, Height: 1})
+	assert.Equal(t, left.X()+left.Width(), right.X())
+}
+
+func TestLimitsAreEnforcedAfterSteps(t *testing.T) {
+	parent := New(Config{Width: 400, Height: 300})
+	minimap := New(Config{}).SetLimits(Limits{MinWidth: 50, MaxWidth: 200, AspectRatio: 1})
+	assert.NoError(t, parent.AddDependent(minimap))
+
+	minimap.Computed(func(b *Box) {
+		b.CopyDimensionsOf(parent)
+	})
+	minimap.Computed(func(b *Box) {
+		b.DecreaseHeight(20)
+	})
+
+	assert.Equal(t, Rect{Width: 200, Height: 200}, minimap.Rect())
+
+	parent.SetDimensions(120, 100)
+	parent.Recalculate()
+	assert.Equal(t, Rect{Width: 80, Height: 80}, minimap.Rect(), "the width should shrink to keep the ratio")
+
+	parent.SetDimensions(10, 10)
+	parent.Recalculate()
+	assert.Equal(t, Rect{Width: 50, Height: 50}, minimap.Rect(), "minimums win")
+
+	minimap.SetDimensions(1000, 10)
+	assert.Equal(t, Rect{Width: 50, Height: 50}, minimap.Rect(), "setters outside of steps are limited too")
+
+	panel := New(Config{Width: 100, Height: 100}).SetMaxSize(0, 60)
+	assert.Equal(t, Rect{Width: 100, Height: 60}, panel.Rect())
+}
+
+func TestSetMarginFlagsWhatLaysTheBoxOut(t *testing.T) {
+	child := New(Config{Width: 20, Height: 10})
+	dependent := Computed(func(b *Box) {
+		b.CopyDimensionsOf(child)
+	})
+	assert.NoError(t, child.AddDependent(dependent))
+
+	// Fits the child and its margin, like a container fitting its contents.
+	parent := Computed(func(b *Box) {
+		margin := child.Margin()
+		b.SetDimensions(child.Width()+margin.Left+margin.Right, child.Height()+margin.Top+margin.Bottom)
+	})
+	assert.Equal(t, Rect{Width: 20, Height: 10}, parent.Rect())
+	dependent.Rect()
+	count := dependent.recalculationCount
+
+	child.SetMargin(config.EqualPadding(5))
+	assert.Equal(t, Rect{Width: 30, Height: 20}, parent.Rect(), "the box reading the margin should be recalculated")
+	assert.Equal(t, Rect{Width: 20, Height: 10}, child.Rect(), "the margin doesn't change the box")
+	dependent.Rect()
+	assert.Equal(t, count+1, dependent.recalculationCount, "dependents should be recalculated")
+
+	count = parent.recalculationCount
+	child.SetMargin(config.EqualPadding(5))
+	parent.Rect()
+	assert.Equal(t, count, parent.recalculationCount, "setting the same margin again changes nothing")
+}
+
+func TestBoundingIncludesMargins(t *testing.T) {
+	a := New(Config{X: 10, Y: 10, Width: 20, Height: 20}).SetMargin(config.EqualPadding(5))
+	b := New(Config{X: 40, Y: 10, Width: 20, Height: 20}).SetMargin(config.Padding{Right: 8})
+
+	assert.Equal(t, Rect{X: 10, Y: 10, Width: 20, Height: 20}, a.Rect(), "the margin doesn't change the box")
+	assert.Equal(t, Rect{X: 5, Y: 5, Width: 63, Height: 30}, Bounding([]*Box{a, b}).Rect())
+}
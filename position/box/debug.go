@@ -5,11 +5,12 @@ import (
 	"strings"
 )
 
-// String implements the Stringer interface for pretty printing a Box
+// String implements the Stringer interface for pretty printing a Box. It shows
+// the box as it is, without recalculating it, so it is safe to log from steps.
 func (b *Box) String() string {
 	// return b.prettyPrint(0, map[*Box]bool{})
 
-	return ""
+	return fmt.Sprintf("Box(%g, %g) %gx%g steps:%d dependents:%d", b.x, b.y, b.width, b.height, len(b.calculationSteps), len(b.dependents))
 }
 
 // prettyPrint returns a formatted string representation of the Box with indentation and colors
@@ -36,8 +37,8 @@ func (b *Box) prettyPrint(level int, visited map[*Box]bool) string {
 	sb.WriteString(fmt.Sprintf("%sBox %p%s {\n", cyan, b, reset))
 
 	// Position and dimensions
-	sb.WriteString(fmt.Sprintf("%s%sPosition: (%d, %d)%s\n", nextIndent, green, b.x, b.y, reset))
-	sb.WriteString(fmt.Sprintf("%s%sDimensions: %dx%d%s\n", nextIndent, green, b.width, b.height, reset))
+	sb.WriteString(fmt.Sprintf("%s%sPosition: (%g, %g)%s\n", nextIndent, green, b.x, b.y, reset))
+	sb.WriteString(fmt.Sprintf("%s%sDimensions: %gx%g%s\n", nextIndent, green, b.width, b.height, reset))
 
 	// Calculation info
 	sb.WriteString(fmt.Sprintf("%s%sCalculation Steps: %d%s\n", nextIndent, blue, len(b.calculationSteps), reset))
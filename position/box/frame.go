@@ -0,0 +1,81 @@
+package box
+
+import "math"
+
+// Frame is a box's position and dimensions before they are snapped to whole
+// pixels. Boxes only keep fractional values when subpixel layout is on.
+type Frame struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+var subpixel bool
+
+// SetSubpixel turns subpixel layout on or off for every box. When it is on,
+// boxes keep fractional positions and dimensions, e.g. from centering or
+// animation, and are only snapped to whole pixels when read through the int
+// accessors, which is what drawing uses. When it is off (the default) every
+// write is snapped straight away.
+func SetSubpixel(enabled bool) {
+	subpixel = enabled
+}
+
+func Subpixel() bool {
+	return subpixel
+}
+
+// Half halves a length, dropping the odd half pixel unless subpixel layout is
+// on. Use it when centering so boxes line up with CenterWithin.
+func Half(length float64) float64 {
+	if subpixel {
+		return length / 2
+	}
+	return math.Trunc(length / 2)
+}
+
+// Rect snaps the frame to whole pixels. The edges are rounded rather than the
+// width and height, so frames that touch still touch once snapped.
+func (f Frame) Rect() Rect {
+	left, top := snap(f.X), snap(f.Y)
+	return Rect{
+		X:      left,
+		Y:      top,
+		Width:  snap(f.X+f.Width) - left,
+		Height: snap(f.Y+f.Height) - top,
+	}
+}
+
+func (r Rect) Frame() Frame {
+	return Frame{X: float64(r.X), Y: float64(r.Y), Width: float64(r.Width), Height: float64(r.Height)}
+}
+
+func snap(v float64) int {
+	return int(math.Round(v))
+}
+
+// Frame returns the box's current position and dimensions without snapping.
+func (b *Box) Frame() Frame {
+	b.recalculateIfNeeded()
+	return b.frame()
+}
+
+// SetFrame sets the box's position and dimensions. They are snapped to whole
+// pixels unless subpixel layout is on.
+func (b *Box) SetFrame(f Frame) *Box {
+	b.setFrame(f)
+	return b
+}
+
+// SetFrameOrigin is SetOrigin with fractional coordinates.
+func (b *Box) SetFrameOrigin(x, y float64) *Box {
+	f := b.frame()
+	f.X, f.Y = x, y
+	b.setFrame(f)
+	return b
+}
+
+func (b *Box) frame() Frame {
+	return Frame{X: b.x, Y: b.y, Width: b.width, Height: b.height}
+}
@@ -0,0 +1,109 @@
+package box
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CycleError is returned when adding a dependent would make a Box depend on
+// itself, directly or through a chain of other boxes.
+type CycleError struct {
+	// Path starts and ends with the same Box, e.g. a -> b -> c -> a.
+	Path []*Box
+}
+
+func (e *CycleError) Error() string {
+	steps := make([]string, len(e.Path))
+	for i, b := range e.Path {
+		steps[i] = fmt.Sprintf("%p", b)
+	}
+	return fmt.Sprintf("box: dependency cycle of length %d: %s", len(e.Path)-1, strings.Join(steps, " -> "))
+}
+
+// pathTo returns the chain of dependents leading from b to target, or nil if
+// target is not reachable from b.
+func (b *Box) pathTo(target *Box, visited map[*Box]bool) []*Box {
+	if b == target {
+		return []*Box{b}
+	}
+	if visited[b] {
+		return nil
+	}
+	visited[b] = true
+
+	for _, dependent := range b.dependents {
+		if path := dependent.pathTo(target, visited); path != nil {
+			return append([]*Box{b}, path...)
+		}
+	}
+	return nil
+}
+
+// cycleWith returns the *CycleError that making dependent depend on b would
+// cause, or nil if it wouldn't cause one.
+func (b *Box) cycleWith(dependent *Box) error {
+	if dependent == nil || dependent == b {
+		return nil
+	}
+	if path := dependent.pathTo(b, map[*Box]bool{}); path != nil {
+		return &CycleError{Path: append([]*Box{b}, path...)}
+	}
+	return nil
+}
+
+// topologicalOrder returns every box reachable from roots through dependents,
+// ordered so that a box always comes before the boxes that depend on it.
+func topologicalOrder(roots []*Box) []*Box {
+	visited := map[*Box]bool{}
+	order := []*Box{}
+
+	var visit func(b *Box)
+	visit = func(b *Box) {
+		if visited[b] {
+			return
+		}
+		visited[b] = true
+		for _, dependent := range b.dependents {
+			visit(dependent)
+		}
+		order = append(order, b)
+	}
+
+	for _, root := range roots {
+		if root != nil {
+			visit(root)
+		}
+	}
+
+	slices.Reverse(order)
+	return order
+}
+
+// calculating holds the boxes whose calculation steps are currently running,
+// innermost last.
+var calculating []*Box
+
+func currentlyCalculating() *Box {
+	if len(calculating) == 0 {
+		return nil
+	}
+	return calculating[len(calculating)-1]
+}
+
+// flag marks every box in order as needing recalculation.
+func flag(order []*Box) {
+	for _, b := range order {
+		b.needsRecalculation = true
+	}
+}
+
+// recalculate flags everything reachable from roots and then recalculates
+// each box once, dependencies first.
+func recalculate(roots []*Box) {
+	order := topologicalOrder(roots)
+	flag(order)
+	for _, b := range order {
+		b.recalculateIfNeeded()
+	}
+}
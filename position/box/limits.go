@@ -0,0 +1,83 @@
+package box
+
+// Limits bound a box's size. They are enforced after the box's calculation
+// steps have run, and whenever its geometry is set from outside a step, so
+// steps can't break them whatever order they run in.
+type Limits struct {
+	MinWidth  int
+	MinHeight int
+
+	// Zero means no maximum.
+	MaxWidth  int
+	MaxHeight int
+
+	// Width divided by height, zero means any. The box shrinks along one axis
+	// to keep the ratio.
+	AspectRatio float64
+}
+
+// Apply returns width and height within the limits. When they can't all be
+// met, the minimums and the aspect ratio win over the maximums.
+func (l Limits) Apply(width, height float64) (float64, float64) {
+	width = l.clamp(width, l.MinWidth, l.MaxWidth)
+	height = l.clamp(height, l.MinHeight, l.MaxHeight)
+
+	if l.AspectRatio <= 0 {
+		return width, height
+	}
+
+	// Shrink to fit the ratio, then grow back along it if that took the box
+	// below either minimum.
+	width = min(width, height*l.AspectRatio)
+	width = max(width, float64(l.MinWidth), float64(l.MinHeight)*l.AspectRatio)
+	return width, width / l.AspectRatio
+}
+
+func (l Limits) clamp(length float64, minimum, maximum int) float64 {
+	if maximum > 0 {
+		length = min(length, float64(maximum))
+	}
+	return max(length, float64(minimum))
+}
+
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+func (b *Box) Limits() Limits {
+	return b.limits
+}
+
+// SetLimits replaces the box's limits and applies them straight away.
+func (b *Box) SetLimits(limits Limits) *Box {
+	b.limits = limits
+	b.setFrame(b.frame())
+	return b
+}
+
+func (b *Box) SetMinSize(width, height int) *Box {
+	l := b.limits
+	l.MinWidth, l.MinHeight = width, height
+	return b.SetLimits(l)
+}
+
+// Zero means no maximum.
+func (b *Box) SetMaxSize(width, height int) *Box {
+	l := b.limits
+	l.MaxWidth, l.MaxHeight = width, height
+	return b.SetLimits(l)
+}
+
+func (b *Box) SetAspectRatio(ratio float64) *Box {
+	l := b.limits
+	l.AspectRatio = ratio
+	return b.SetLimits(l)
+}
+
+func (b *Box) limited(f Frame) Frame {
+	if b.limits.IsZero() {
+		return f
+	}
+	f.Width, f.Height = b.limits.Apply(f.Width, f.Height)
+	return f
+}
@@ -0,0 +1,61 @@
+package box
+
+import (
+	"slices"
+
+	"github.com/jhuggett/thingamabob/config"
+)
+
+// Margin is the space a box asks to be left around it. Layout containers
+// place the box inside its margin, and Bounding includes it. It doesn't change
+// the box itself.
+func (b *Box) Margin() config.Padding {
+	// Whatever is laying the box out reads the margin from its steps, so it is
+	// the box to flag when the margin changes.
+	if reader := currentlyCalculating(); reader != nil && reader != b && !slices.Contains(b.marginReaders, reader) {
+		b.marginReaders = append(b.marginReaders, reader)
+	}
+	return b.margin
+}
+
+// SetMargin replaces the box's margin, and flags the box and the boxes whose
+// steps have read its margin for recalculation.
+func (b *Box) SetMargin(margin config.Padding) *Box {
+	if margin == b.margin {
+		return b
+	}
+
+	b.margin = margin
+	b.FlagNeedsRecalculation()
+	for _, reader := range b.marginReaders {
+		reader.FlagNeedsRecalculation()
+	}
+	return b
+}
+
+// MarginFrame is the box's frame grown by its margin: the space it takes up in
+// a layout.
+func (b *Box) MarginFrame() Frame {
+	return b.Frame().Outset(b.margin)
+}
+
+// Inset moves each edge of the frame inwards by the matching padding. The
+// frame never ends up with a negative width or height.
+func (f Frame) Inset(padding config.Padding) Frame {
+	return Frame{
+		X:      f.X + float64(padding.Left),
+		Y:      f.Y + float64(padding.Top),
+		Width:  max(f.Width-float64(padding.Left+padding.Right), 0),
+		Height: max(f.Height-float64(padding.Top+padding.Bottom), 0),
+	}
+}
+
+// Outset moves each edge of the frame outwards by the matching padding.
+func (f Frame) Outset(padding config.Padding) Frame {
+	return f.Inset(config.Padding{
+		Top:    -padding.Top,
+		Right:  -padding.Right,
+		Bottom: -padding.Bottom,
+		Left:   -padding.Left,
+	})
+}
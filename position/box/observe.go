@@ -0,0 +1,82 @@
+package box
+
+// Rect is a snapshot of a Box's position and dimensions.
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+type changeObserver struct {
+	onChange func(old, new Rect)
+}
+
+// Rect returns the box's current position and dimensions.
+func (b *Box) Rect() Rect {
+	b.recalculateIfNeeded()
+	return b.rect()
+}
+
+func (b *Box) SetRect(r Rect) *Box {
+	b.setRect(r)
+	return b
+}
+
+func (b *Box) rect() Rect {
+	return b.frame().Rect()
+}
+
+// OnChange registers fn to be called whenever a recalculation or a setter
+// actually changes the box's position or dimensions, once snapped to whole
+// pixels. Changes made by the box's own calculation steps are reported once,
+// after all of them have run. Returns a function that unregisters fn.
+func (b *Box) OnChange(fn func(old, new Rect)) func() {
+	observer := &changeObserver{onChange: fn}
+	b.observers = append(b.observers, observer)
+
+	return func() {
+		for i, o := range b.observers {
+			if o == observer {
+				b.observers = append(b.observers[:i], b.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (b *Box) setRect(r Rect) {
+	b.setFrame(r.Frame())
+}
+
+// setFrame is the one place a box's geometry is written.
+func (b *Box) setFrame(f Frame) {
+	// Steps are free to pass through sizes outside the limits on their way
+	// to the final one.
+	if !b.isCalculating {
+		f = b.limited(f)
+	}
+	if !subpixel {
+		f = f.Rect().Frame()
+	}
+
+	old := b.rect()
+	b.x, b.y, b.width, b.height = f.X, f.Y, f.Width, f.Height
+
+	if !b.isCalculating {
+		b.notifyIfChanged(old)
+	}
+}
+
+func (b *Box) notifyIfChanged(old Rect) {
+	current := b.rect()
+	if current == old {
+		return
+	}
+
+	// Copied so observers can unregister themselves while being notified.
+	observers := append([]*changeObserver{}, b.observers...)
+	for _, observer := range observers {
+		observer.onChange(old, current)
+	}
+}
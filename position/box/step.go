@@ -0,0 +1,120 @@
+package box
+
+import "slices"
+
+// Step is a calculation step registered on a Box. Keep hold of it to remove
+// the step, and the dependents registered with it, later on.
+type Step struct {
+	name      string
+	calculate func(*Box)
+
+	box        *Box
+	dependents []*Box
+}
+
+// Name is empty for steps added with Computed.
+func (s *Step) Name() string {
+	return s.name
+}
+
+func (s *Step) Box() *Box {
+	return s.box
+}
+
+func (s *Step) Dependents() []*Box {
+	return s.dependents
+}
+
+// Remove takes the step off its box, see Box.RemoveStep.
+func (s *Step) Remove() {
+	if s.box != nil {
+		s.box.RemoveStep(s)
+	}
+}
+
+// Like Computed, but if the box already has a step with the same name, that
+// step (and the dependents registered with it) is replaced in place, so
+// re-running a Setup does not pile up steps.
+//
+// Panics with a *CycleError if a dependent would make the box depend on
+// itself. The box is left as it was when that happens.
+func (b *Box) NamedComputed(name string, calculateFn func(*Box), dependents ...*Box) *Step {
+	if calculateFn == nil {
+		panic("calculateFn cannot be nil")
+	}
+	for _, dependent := range dependents {
+		if err := b.cycleWith(dependent); err != nil {
+			panic(err)
+		}
+	}
+
+	step := &Step{
+		name:      name,
+		calculate: calculateFn,
+		box:       b,
+	}
+
+	var existing *Step
+	if name != "" {
+		existing = b.Step(name)
+	}
+
+	if existing != nil {
+		b.detachStepDependents(existing)
+		existing.box = nil
+		b.calculationSteps[slices.Index(b.calculationSteps, existing)] = step
+	} else {
+		b.calculationSteps = append(b.calculationSteps, step)
+	}
+
+	for _, dependent := range dependents {
+		if err := b.AddDependent(dependent); err != nil {
+			panic(err)
+		}
+		step.dependents = append(step.dependents, dependent)
+	}
+
+	b.FlagNeedsRecalculation()
+	return step
+}
+
+// Step returns the step with the given name, or nil if there is none.
+func (b *Box) Step(name string) *Step {
+	for _, step := range b.calculationSteps {
+		if step.name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// RemoveStep removes the step and detaches any dependents that were registered
+// with it.
+func (b *Box) RemoveStep(step *Step) *Box {
+	index := slices.Index(b.calculationSteps, step)
+	if index == -1 {
+		return b
+	}
+
+	b.detachStepDependents(step)
+	step.box = nil
+	b.calculationSteps = slices.Delete(b.calculationSteps, index, index+1)
+
+	b.FlagNeedsRecalculation()
+	return b
+}
+
+// RemoveNamedStep removes the step with the given name, if there is one.
+func (b *Box) RemoveNamedStep(name string) *Box {
+	if step := b.Step(name); step != nil {
+		b.RemoveStep(step)
+	}
+	return b
+}
+
+func (b *Box) detachStepDependents(step *Step) {
+	for _, dependent := range step.dependents {
+		b.RemoveDependent(dependent)
+	}
+	step.dependents = nil
+}
@@ -0,0 +1,93 @@
+package constraint
+
+import "fmt"
+
+// Strengths decide which constraints give way when they can't all be
+// satisfied. A stronger constraint always wins over any number of weaker ones.
+var (
+	Required = NewStrength(1000, 1000, 1000, 1)
+	Strong   = NewStrength(1, 0, 0, 1)
+	Medium   = NewStrength(0, 1, 0, 1)
+	Weak     = NewStrength(0, 0, 1, 1)
+)
+
+// NewStrength combines strong, medium and weak parts (each 0 to 1000) into a
+// single strength, scaled by weight.
+func NewStrength(strong, medium, weak, weight float64) float64 {
+	clamp := func(v float64) float64 {
+		return max(0, min(1000, v))
+	}
+	return clamp(strong*weight)*1_000_000 + clamp(medium*weight)*1_000 + clamp(weak*weight)
+}
+
+func clipStrength(strength float64) float64 {
+	return max(0, min(Required, strength))
+}
+
+type Operator int
+
+const (
+	LessOrEqualTo Operator = iota
+	EqualTo
+	GreaterOrEqualTo
+)
+
+func (o Operator) String() string {
+	switch o {
+	case LessOrEqualTo:
+		return "<="
+	case GreaterOrEqualTo:
+		return ">="
+	default:
+		return "=="
+	}
+}
+
+// Constraint is a linear relation between two expressions, e.g.
+// a.Right + 8 == b.Left. Constraints are required unless given a weaker
+// strength with At.
+type Constraint struct {
+	// lhs - rhs, compared with zero.
+	expression Expression
+	operator   Operator
+	strength   float64
+}
+
+func newConstraint(lhs, rhs Expressible, operator Operator) *Constraint {
+	return &Constraint{
+		expression: lhs.Expression().Minus(rhs).reduced(),
+		operator:   operator,
+		strength:   Required,
+	}
+}
+
+func Equal(lhs, rhs Expressible) *Constraint {
+	return newConstraint(lhs, rhs, EqualTo)
+}
+
+func LessOrEqual(lhs, rhs Expressible) *Constraint {
+	return newConstraint(lhs, rhs, LessOrEqualTo)
+}
+
+func GreaterOrEqual(lhs, rhs Expressible) *Constraint {
+	return newConstraint(lhs, rhs, GreaterOrEqualTo)
+}
+
+// At sets the constraint's strength. Use it before adding the constraint to
+// a solver.
+func (c *Constraint) At(strength float64) *Constraint {
+	c.strength = clipStrength(strength)
+	return c
+}
+
+func (c *Constraint) Strength() float64 {
+	return c.strength
+}
+
+func (c *Constraint) Operator() Operator {
+	return c.operator
+}
+
+func (c *Constraint) String() string {
+	return fmt.Sprintf("%s %s 0 @%g", c.expression, c.operator, c.strength)
+}
@@ -0,0 +1,127 @@
+package constraint
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variable is an unknown the solver finds a value for.
+type Variable struct {
+	name  string
+	value float64
+}
+
+func NewVariable(name string) *Variable {
+	return &Variable{name: name}
+}
+
+func (v *Variable) Name() string {
+	return v.name
+}
+
+// Value is only updated when the solver's variables are updated.
+func (v *Variable) Value() float64 {
+	return v.value
+}
+
+func (v *Variable) Expression() Expression {
+	return Expression{Terms: []Term{{Variable: v, Coefficient: 1}}}
+}
+
+func (v *Variable) Plus(other Expressible) Expression {
+	return v.Expression().Plus(other)
+}
+
+func (v *Variable) Minus(other Expressible) Expression {
+	return v.Expression().Minus(other)
+}
+
+func (v *Variable) Times(coefficient float64) Expression {
+	return v.Expression().Times(coefficient)
+}
+
+// Term is a variable multiplied by a coefficient.
+type Term struct {
+	Variable    *Variable
+	Coefficient float64
+}
+
+// Expression is a linear combination of variables plus a constant.
+type Expression struct {
+	Terms    []Term
+	Constant float64
+}
+
+// Expressible is anything that can be used as one side of a constraint:
+// variables, expressions and constants.
+type Expressible interface {
+	Expression() Expression
+}
+
+func (e Expression) Expression() Expression {
+	return e
+}
+
+// Constant is a fixed value in an expression, e.g. the 8 in a.Right + 8.
+type Constant float64
+
+func (c Constant) Expression() Expression {
+	return Expression{Constant: float64(c)}
+}
+
+func (e Expression) Plus(other Expressible) Expression {
+	o := other.Expression()
+
+	terms := make([]Term, 0, len(e.Terms)+len(o.Terms))
+	terms = append(terms, e.Terms...)
+	terms = append(terms, o.Terms...)
+
+	return Expression{Terms: terms, Constant: e.Constant + o.Constant}
+}
+
+func (e Expression) Minus(other Expressible) Expression {
+	return e.Plus(other.Expression().Times(-1))
+}
+
+func (e Expression) Times(coefficient float64) Expression {
+	terms := make([]Term, len(e.Terms))
+	for i, term := range e.Terms {
+		terms[i] = Term{Variable: term.Variable, Coefficient: term.Coefficient * coefficient}
+	}
+	return Expression{Terms: terms, Constant: e.Constant * coefficient}
+}
+
+// Value evaluates the expression with the variables' current values.
+func (e Expression) Value() float64 {
+	value := e.Constant
+	for _, term := range e.Terms {
+		value += term.Variable.value * term.Coefficient
+	}
+	return value
+}
+
+// reduced combines the terms that share a variable.
+func (e Expression) reduced() Expression {
+	index := map[*Variable]int{}
+	terms := []Term{}
+	for _, term := range e.Terms {
+		if i, ok := index[term.Variable]; ok {
+			terms[i].Coefficient += term.Coefficient
+			continue
+		}
+		index[term.Variable] = len(terms)
+		terms = append(terms, term)
+	}
+	return Expression{Terms: terms, Constant: e.Constant}
+}
+
+func (e Expression) String() string {
+	parts := []string{}
+	for _, term := range e.Terms {
+		parts = append(parts, fmt.Sprintf("%g*%s", term.Coefficient, term.Variable.name))
+	}
+	if e.Constant != 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%g", e.Constant))
+	}
+	return strings.Join(parts, " + ")
+}
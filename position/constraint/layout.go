@@ -0,0 +1,178 @@
+package constraint
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// Rect holds the variables the solver finds for one box.
+type Rect struct {
+	Left   *Variable
+	Top    *Variable
+	Width  *Variable
+	Height *Variable
+}
+
+func NewRect(name string) *Rect {
+	return &Rect{
+		Left:   NewVariable(name + ".left"),
+		Top:    NewVariable(name + ".top"),
+		Width:  NewVariable(name + ".width"),
+		Height: NewVariable(name + ".height"),
+	}
+}
+
+func (r *Rect) Right() Expression {
+	return r.Left.Plus(r.Width)
+}
+
+func (r *Rect) Bottom() Expression {
+	return r.Top.Plus(r.Height)
+}
+
+func (r *Rect) CenterX() Expression {
+	return r.Left.Plus(r.Width.Times(0.5))
+}
+
+func (r *Rect) CenterY() Expression {
+	return r.Top.Plus(r.Height.Times(0.5))
+}
+
+// Frame returns the current values of the variables.
+func (r *Rect) Frame() box.Frame {
+	return box.Frame{
+		X:      r.Left.Value(),
+		Y:      r.Top.Value(),
+		Width:  r.Width.Value(),
+		Height: r.Height.Value(),
+	}
+}
+
+// Rect snaps the current values of the variables to whole pixels.
+func (r *Rect) Rect() box.Rect {
+	return r.Frame().Rect()
+}
+
+const layoutStep = "constraint.layout"
+
+// Tracked boxes give way to required constraints only.
+var trackStrength = NewStrength(1000, 0, 0, 1)
+
+// Layout solves constraints between boxes and writes the results into them.
+type Layout struct {
+	solver *Solver
+
+	bound      []*box.Box
+	unregister []func()
+}
+
+func NewLayout() *Layout {
+	return &Layout{solver: NewSolver()}
+}
+
+func (l *Layout) Solver() *Solver {
+	return l.solver
+}
+
+// Bind makes b's geometry come from the solver, through a calculation step
+// added to b. Its width and height are kept non-negative.
+func (l *Layout) Bind(b *box.Box, name string) *Rect {
+	r := NewRect(name)
+
+	l.mustAdd(
+		GreaterOrEqual(r.Width, Constant(0)),
+		GreaterOrEqual(r.Height, Constant(0)),
+	)
+
+	b.NamedComputed(layoutStep, func(b *box.Box) {
+		b.SetFrame(r.Frame())
+	})
+	l.bound = append(l.bound, b)
+
+	return r
+}
+
+// Track feeds b's geometry into the solver and follows it as it changes, e.g.
+// for the page a layout has to fit within. Only required constraints can
+// override it.
+func (l *Layout) Track(b *box.Box, name string) *Rect {
+	r := NewRect(name)
+
+	for _, v := range []*Variable{r.Left, r.Top, r.Width, r.Height} {
+		if err := l.solver.AddEditVariable(v, trackStrength); err != nil {
+			panic(err)
+		}
+	}
+	l.suggest(r, b.Frame())
+
+	l.unregister = append(l.unregister, b.OnChange(func(_, _ box.Rect) {
+		l.suggest(r, b.Frame())
+		l.Solve()
+	}))
+
+	return r
+}
+
+func (l *Layout) suggest(r *Rect, frame box.Frame) {
+	variables := []*Variable{r.Left, r.Top, r.Width, r.Height}
+	values := []float64{frame.X, frame.Y, frame.Width, frame.Height}
+	for i, v := range variables {
+		if err := l.solver.SuggestValue(v, values[i]); err != nil {
+			slog.Error("Failed to suggest tracked box value", "variable", v.Name(), "error", err)
+		}
+	}
+}
+
+// Add adds constraints to the layout. It stops at the first one that can't be
+// added. Call Solve to apply the result.
+func (l *Layout) Add(constraints ...*Constraint) error {
+	for _, c := range constraints {
+		if err := l.solver.AddConstraint(c); err != nil {
+			return fmt.Errorf("adding %s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+func (l *Layout) mustAdd(constraints ...*Constraint) {
+	if err := l.Add(constraints...); err != nil {
+		panic(err)
+	}
+}
+
+// Remove removes constraints from the layout. Call Solve to apply the result.
+func (l *Layout) Remove(constraints ...*Constraint) error {
+	for _, c := range constraints {
+		if err := l.solver.RemoveConstraint(c); err != nil {
+			return fmt.Errorf("removing %s: %w", c, err)
+		}
+	}
+	return nil
+}
+
+// Solve updates the variables and recalculates the bound boxes.
+func (l *Layout) Solve() {
+	l.solver.UpdateVariables()
+
+	box.Batch(func() {
+		for _, b := range l.bound {
+			b.Recalculate()
+		}
+	})
+}
+
+// Close stops following tracked boxes and removes the layout's steps from the
+// bound boxes.
+func (l *Layout) Close() {
+	for _, unregister := range l.unregister {
+		unregister()
+	}
+	l.unregister = nil
+
+	for _, b := range l.bound {
+		b.RemoveNamedStep(layoutStep)
+	}
+	l.bound = nil
+}
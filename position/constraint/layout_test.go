@@ -0,0 +1,55 @@
+package constraint
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutFollowsTrackedBox(t *testing.T) {
+	page := box.Zeroed().SetDimensions(1000, 600)
+	sidebarBox, contentBox := box.Zeroed(), box.Zeroed()
+
+	l := NewLayout()
+	p := l.Track(page, "page")
+	sidebar := l.Bind(sidebarBox, "sidebar")
+	content := l.Bind(contentBox, "content")
+
+	require.NoError(t, l.Add(
+		Equal(sidebar.Left, p.Left),
+		Equal(sidebar.Top, p.Top),
+		Equal(sidebar.Height, p.Height),
+		GreaterOrEqual(sidebar.Width, Constant(150)),
+		Equal(sidebar.Width, p.Width.Times(0.2)).At(Strong),
+
+		Equal(sidebar.Right().Plus(Constant(8)), content.Left),
+		Equal(content.Top, p.Top),
+		Equal(content.Right(), p.Left.Plus(p.Width)),
+		Equal(content.Bottom(), p.Bottom()),
+	))
+	l.Solve()
+
+	assert.Equal(t, box.Rect{X: 0, Y: 0, Width: 200, Height: 600}, sidebarBox.Rect())
+	assert.Equal(t, box.Rect{X: 208, Y: 0, Width: 792, Height: 600}, contentBox.Rect())
+
+	page.SetDimensions(500, 300)
+
+	assert.Equal(t, box.Rect{X: 0, Y: 0, Width: 150, Height: 300}, sidebarBox.Rect())
+	assert.Equal(t, box.Rect{X: 158, Y: 0, Width: 342, Height: 300}, contentBox.Rect())
+
+	l.Close()
+	page.SetDimensions(1000, 600)
+
+	assert.Equal(t, box.Rect{X: 158, Y: 0, Width: 342, Height: 300}, contentBox.Rect())
+	assert.Empty(t, contentBox.CalculationSteps())
+}
+
+func TestLayoutRejectsUnsatisfiableConstraints(t *testing.T) {
+	l := NewLayout()
+	r := l.Bind(box.Zeroed(), "box")
+
+	err := l.Add(Equal(r.Width, Constant(-10)))
+	assert.ErrorIs(t, err, ErrUnsatisfiableConstraint)
+}
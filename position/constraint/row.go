@@ -0,0 +1,99 @@
+package constraint
+
+// row is one row of the simplex tableau: a basic symbol equals constant plus
+// the sum of cells.
+type row struct {
+	cells    map[symbol]float64
+	constant float64
+}
+
+func newRow(constant float64) *row {
+	return &row{cells: map[symbol]float64{}, constant: constant}
+}
+
+func (r *row) copy() *row {
+	c := newRow(r.constant)
+	for sym, coefficient := range r.cells {
+		c.cells[sym] = coefficient
+	}
+	return c
+}
+
+func (r *row) symbols() []symbol {
+	return sortedSymbols(r.cells)
+}
+
+func (r *row) add(value float64) float64 {
+	r.constant += value
+	return r.constant
+}
+
+func (r *row) insert(sym symbol, coefficient float64) {
+	value := r.cells[sym] + coefficient
+	if nearZero(value) {
+		delete(r.cells, sym)
+		return
+	}
+	r.cells[sym] = value
+}
+
+// insertRow adds other, multiplied by coefficient, to this row.
+func (r *row) insertRow(other *row, coefficient float64) {
+	r.constant += other.constant * coefficient
+	for sym, c := range other.cells {
+		r.insert(sym, c*coefficient)
+	}
+}
+
+func (r *row) remove(sym symbol) {
+	delete(r.cells, sym)
+}
+
+func (r *row) reverseSign() {
+	r.constant = -r.constant
+	for sym, coefficient := range r.cells {
+		r.cells[sym] = -coefficient
+	}
+}
+
+// solveFor rearranges the row so that it defines sym, which is removed from
+// the cells.
+func (r *row) solveFor(sym symbol) {
+	coefficient := -1.0 / r.cells[sym]
+	delete(r.cells, sym)
+
+	r.constant *= coefficient
+	for s, c := range r.cells {
+		r.cells[s] = c * coefficient
+	}
+}
+
+// solveForPair rearranges a row that currently defines lhs so that it
+// defines rhs instead.
+func (r *row) solveForPair(lhs, rhs symbol) {
+	r.insert(lhs, -1)
+	r.solveFor(rhs)
+}
+
+func (r *row) coefficientFor(sym symbol) float64 {
+	return r.cells[sym]
+}
+
+// substitute replaces sym in this row with the row that defines it.
+func (r *row) substitute(sym symbol, other *row) {
+	coefficient, ok := r.cells[sym]
+	if !ok {
+		return
+	}
+	delete(r.cells, sym)
+	r.insertRow(other, coefficient)
+}
+
+func (r *row) allDummies() bool {
+	for sym := range r.cells {
+		if sym.kind != dummySymbol {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,538 @@
+package constraint
+
+import (
+	"errors"
+	"math"
+	"slices"
+)
+
+var (
+	ErrDuplicateConstraint        = errors.New("constraint: constraint has already been added")
+	ErrUnknownConstraint          = errors.New("constraint: constraint has not been added")
+	ErrUnsatisfiableConstraint    = errors.New("constraint: required constraint cannot be satisfied")
+	ErrDuplicateEditVariable      = errors.New("constraint: variable is already being edited")
+	ErrUnknownEditVariable        = errors.New("constraint: variable is not being edited")
+	ErrRequiredEditVariable       = errors.New("constraint: edit variables cannot be required")
+	ErrInternalSolverInconsistent = errors.New("constraint: solver is in an inconsistent state")
+)
+
+type symbolKind int
+
+const (
+	invalidSymbol symbolKind = iota
+	externalSymbol
+	slackSymbol
+	errorSymbol
+	dummySymbol
+)
+
+type symbol struct {
+	id   int
+	kind symbolKind
+}
+
+func (s symbol) valid() bool {
+	return s.kind != invalidSymbol
+}
+
+// tag records the symbols a constraint added to the tableau.
+type tag struct {
+	marker symbol
+	other  symbol
+}
+
+type editInfo struct {
+	tag        tag
+	constraint *Constraint
+	constant   float64
+}
+
+// Solver is an incremental Cassowary constraint solver. Constraints and edit
+// variables can be added and removed at any time; call UpdateVariables to copy
+// the current solution into the variables.
+type Solver struct {
+	constraints map[*Constraint]tag
+	rows        map[symbol]*row
+	variables   map[*Variable]symbol
+	edits       map[*Variable]*editInfo
+
+	infeasibleRows []symbol
+	objective      *row
+	artificial     *row
+
+	nextID int
+}
+
+func NewSolver() *Solver {
+	return &Solver{
+		constraints: map[*Constraint]tag{},
+		rows:        map[symbol]*row{},
+		variables:   map[*Variable]symbol{},
+		edits:       map[*Variable]*editInfo{},
+		objective:   newRow(0),
+	}
+}
+
+func (s *Solver) newSymbol(kind symbolKind) symbol {
+	s.nextID++
+	return symbol{id: s.nextID, kind: kind}
+}
+
+func (s *Solver) HasConstraint(c *Constraint) bool {
+	_, ok := s.constraints[c]
+	return ok
+}
+
+func (s *Solver) AddConstraint(c *Constraint) error {
+	if s.HasConstraint(c) {
+		return ErrDuplicateConstraint
+	}
+
+	t := tag{}
+	r := s.createRow(c, &t)
+	subject := chooseSubject(r, t)
+
+	if !subject.valid() && r.allDummies() {
+		if !nearZero(r.constant) {
+			return ErrUnsatisfiableConstraint
+		}
+		subject = t.marker
+	}
+
+	if !subject.valid() {
+		ok, err := s.addWithArtificialVariable(r)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrUnsatisfiableConstraint
+		}
+	} else {
+		r.solveFor(subject)
+		s.substitute(subject, r)
+		s.rows[subject] = r
+	}
+
+	s.constraints[c] = t
+
+	return s.optimize(s.objective)
+}
+
+func (s *Solver) RemoveConstraint(c *Constraint) error {
+	t, ok := s.constraints[c]
+	if !ok {
+		return ErrUnknownConstraint
+	}
+	delete(s.constraints, c)
+
+	s.removeMarkerEffects(t.marker, c.strength)
+	s.removeMarkerEffects(t.other, c.strength)
+
+	if _, ok := s.rows[t.marker]; ok {
+		delete(s.rows, t.marker)
+	} else {
+		leaving, ok := s.markerLeavingSymbol(t.marker)
+		if !ok {
+			return ErrInternalSolverInconsistent
+		}
+
+		r := s.rows[leaving]
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, t.marker)
+		s.substitute(t.marker, r)
+	}
+
+	return s.optimize(s.objective)
+}
+
+func (s *Solver) HasEditVariable(v *Variable) bool {
+	_, ok := s.edits[v]
+	return ok
+}
+
+// AddEditVariable lets v be given values with SuggestValue. The suggestions
+// are honoured as far as constraints of at least strength allow.
+func (s *Solver) AddEditVariable(v *Variable, strength float64) error {
+	if s.HasEditVariable(v) {
+		return ErrDuplicateEditVariable
+	}
+
+	strength = clipStrength(strength)
+	if strength == Required {
+		return ErrRequiredEditVariable
+	}
+
+	c := Equal(v, Constant(0)).At(strength)
+	if err := s.AddConstraint(c); err != nil {
+		return err
+	}
+
+	s.edits[v] = &editInfo{tag: s.constraints[c], constraint: c}
+	return nil
+}
+
+func (s *Solver) RemoveEditVariable(v *Variable) error {
+	info, ok := s.edits[v]
+	if !ok {
+		return ErrUnknownEditVariable
+	}
+
+	if err := s.RemoveConstraint(info.constraint); err != nil {
+		return err
+	}
+	delete(s.edits, v)
+	return nil
+}
+
+// SuggestValue asks for an edit variable to take value, re-solving
+// incrementally from the current solution.
+func (s *Solver) SuggestValue(v *Variable, value float64) error {
+	info, ok := s.edits[v]
+	if !ok {
+		return ErrUnknownEditVariable
+	}
+
+	delta := value - info.constant
+	info.constant = value
+
+	if r, ok := s.rows[info.tag.marker]; ok {
+		if r.add(-delta) < 0 {
+			s.infeasibleRows = append(s.infeasibleRows, info.tag.marker)
+		}
+		return s.dualOptimize()
+	}
+
+	if r, ok := s.rows[info.tag.other]; ok {
+		if r.add(delta) < 0 {
+			s.infeasibleRows = append(s.infeasibleRows, info.tag.other)
+		}
+		return s.dualOptimize()
+	}
+
+	for _, sym := range sortedSymbols(s.rows) {
+		r := s.rows[sym]
+		coefficient := r.coefficientFor(info.tag.marker)
+		if coefficient != 0 && r.add(delta*coefficient) < 0 && sym.kind != externalSymbol {
+			s.infeasibleRows = append(s.infeasibleRows, sym)
+		}
+	}
+	return s.dualOptimize()
+}
+
+// UpdateVariables copies the current solution into the variables.
+func (s *Solver) UpdateVariables() {
+	for v, sym := range s.variables {
+		if r, ok := s.rows[sym]; ok {
+			v.value = r.constant
+		} else {
+			v.value = 0
+		}
+	}
+}
+
+func (s *Solver) symbolFor(v *Variable) symbol {
+	if sym, ok := s.variables[v]; ok {
+		return sym
+	}
+	sym := s.newSymbol(externalSymbol)
+	s.variables[v] = sym
+	return sym
+}
+
+// createRow turns a constraint into a tableau row, adding the slack, error
+// and dummy symbols it needs.
+func (s *Solver) createRow(c *Constraint, t *tag) *row {
+	r := newRow(c.expression.Constant)
+
+	for _, term := range c.expression.Terms {
+		if nearZero(term.Coefficient) {
+			continue
+		}
+
+		sym := s.symbolFor(term.Variable)
+		if basic, ok := s.rows[sym]; ok {
+			r.insertRow(basic, term.Coefficient)
+		} else {
+			r.insert(sym, term.Coefficient)
+		}
+	}
+
+	switch c.operator {
+	case LessOrEqualTo, GreaterOrEqualTo:
+		coefficient := 1.0
+		if c.operator == GreaterOrEqualTo {
+			coefficient = -1.0
+		}
+
+		slack := s.newSymbol(slackSymbol)
+		t.marker = slack
+		r.insert(slack, coefficient)
+
+		if c.strength < Required {
+			errorSym := s.newSymbol(errorSymbol)
+			t.other = errorSym
+			r.insert(errorSym, -coefficient)
+			s.objective.insert(errorSym, c.strength)
+		}
+	case EqualTo:
+		if c.strength < Required {
+			plus := s.newSymbol(errorSymbol)
+			minus := s.newSymbol(errorSymbol)
+			t.marker = plus
+			t.other = minus
+			r.insert(plus, -1)
+			r.insert(minus, 1)
+			s.objective.insert(plus, c.strength)
+			s.objective.insert(minus, c.strength)
+		} else {
+			dummy := s.newSymbol(dummySymbol)
+			t.marker = dummy
+			r.insert(dummy, 1)
+		}
+	}
+
+	if r.constant < 0 {
+		r.reverseSign()
+	}
+
+	return r
+}
+
+// chooseSubject picks the symbol to solve a new row for: any external
+// symbol, otherwise a negative slack or error marker.
+func chooseSubject(r *row, t tag) symbol {
+	for _, sym := range r.symbols() {
+		if sym.kind == externalSymbol {
+			return sym
+		}
+	}
+
+	if t.marker.kind == slackSymbol || t.marker.kind == errorSymbol {
+		if r.coefficientFor(t.marker) < 0 {
+			return t.marker
+		}
+	}
+	if t.other.kind == slackSymbol || t.other.kind == errorSymbol {
+		if r.coefficientFor(t.other) < 0 {
+			return t.other
+		}
+	}
+
+	return symbol{}
+}
+
+func (s *Solver) addWithArtificialVariable(r *row) (bool, error) {
+	art := s.newSymbol(slackSymbol)
+	s.rows[art] = r.copy()
+	s.artificial = r.copy()
+
+	if err := s.optimize(s.artificial); err != nil {
+		return false, err
+	}
+	success := nearZero(s.artificial.constant)
+	s.artificial = nil
+
+	if basic, ok := s.rows[art]; ok {
+		delete(s.rows, art)
+		if len(basic.cells) == 0 {
+			return success, nil
+		}
+
+		entering := anyPivotableSymbol(basic)
+		if !entering.valid() {
+			return false, nil
+		}
+		basic.solveForPair(art, entering)
+		s.substitute(entering, basic)
+		s.rows[entering] = basic
+	}
+
+	for _, basic := range s.rows {
+		basic.remove(art)
+	}
+	s.objective.remove(art)
+
+	return success, nil
+}
+
+// substitute replaces sym with r everywhere in the tableau.
+func (s *Solver) substitute(sym symbol, r *row) {
+	for _, basicSym := range sortedSymbols(s.rows) {
+		basic := s.rows[basicSym]
+		basic.substitute(sym, r)
+		if basicSym.kind != externalSymbol && basic.constant < 0 {
+			s.infeasibleRows = append(s.infeasibleRows, basicSym)
+		}
+	}
+
+	s.objective.substitute(sym, r)
+	if s.artificial != nil {
+		s.artificial.substitute(sym, r)
+	}
+}
+
+// optimize runs the primal simplex until objective can't be improved.
+func (s *Solver) optimize(objective *row) error {
+	for {
+		entering := enteringSymbol(objective)
+		if !entering.valid() {
+			return nil
+		}
+
+		leaving, ok := s.leavingSymbol(entering)
+		if !ok {
+			return ErrInternalSolverInconsistent
+		}
+
+		r := s.rows[leaving]
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+}
+
+// dualOptimize restores feasibility after edit variables change.
+func (s *Solver) dualOptimize() error {
+	for len(s.infeasibleRows) > 0 {
+		leaving := s.infeasibleRows[len(s.infeasibleRows)-1]
+		s.infeasibleRows = s.infeasibleRows[:len(s.infeasibleRows)-1]
+
+		r, ok := s.rows[leaving]
+		if !ok || nearZero(r.constant) || r.constant >= 0 {
+			continue
+		}
+
+		entering := s.dualEnteringSymbol(r)
+		if !entering.valid() {
+			return ErrInternalSolverInconsistent
+		}
+
+		delete(s.rows, leaving)
+		r.solveForPair(leaving, entering)
+		s.substitute(entering, r)
+		s.rows[entering] = r
+	}
+	return nil
+}
+
+func enteringSymbol(objective *row) symbol {
+	for _, sym := range objective.symbols() {
+		if sym.kind != dummySymbol && objective.cells[sym] < 0 {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+func (s *Solver) dualEnteringSymbol(r *row) symbol {
+	entering := symbol{}
+	ratio := math.MaxFloat64
+
+	for _, sym := range r.symbols() {
+		coefficient := r.cells[sym]
+		if coefficient > 0 && sym.kind != dummySymbol {
+			candidate := s.objective.coefficientFor(sym) / coefficient
+			if candidate < ratio {
+				ratio = candidate
+				entering = sym
+			}
+		}
+	}
+	return entering
+}
+
+func anyPivotableSymbol(r *row) symbol {
+	for _, sym := range r.symbols() {
+		if sym.kind == slackSymbol || sym.kind == errorSymbol {
+			return sym
+		}
+	}
+	return symbol{}
+}
+
+func (s *Solver) leavingSymbol(entering symbol) (symbol, bool) {
+	ratio := math.MaxFloat64
+	found := symbol{}
+
+	for _, sym := range sortedSymbols(s.rows) {
+		if sym.kind == externalSymbol {
+			continue
+		}
+
+		coefficient := s.rows[sym].coefficientFor(entering)
+		if coefficient < 0 {
+			candidate := -s.rows[sym].constant / coefficient
+			if candidate < ratio {
+				ratio = candidate
+				found = sym
+			}
+		}
+	}
+	return found, found.valid()
+}
+
+// markerLeavingSymbol finds the row to pivot out when removing a constraint
+// whose marker is not basic.
+func (s *Solver) markerLeavingSymbol(marker symbol) (symbol, bool) {
+	firstRatio, secondRatio := math.MaxFloat64, math.MaxFloat64
+	var first, second, third symbol
+
+	for _, sym := range sortedSymbols(s.rows) {
+		r := s.rows[sym]
+		coefficient := r.coefficientFor(marker)
+		if coefficient == 0 {
+			continue
+		}
+
+		switch {
+		case sym.kind == externalSymbol:
+			third = sym
+		case coefficient < 0:
+			if ratio := -r.constant / coefficient; ratio < firstRatio {
+				firstRatio = ratio
+				first = sym
+			}
+		default:
+			if ratio := r.constant / coefficient; ratio < secondRatio {
+				secondRatio = ratio
+				second = sym
+			}
+		}
+	}
+
+	for _, sym := range []symbol{first, second, third} {
+		if sym.valid() {
+			return sym, true
+		}
+	}
+	return symbol{}, false
+}
+
+func (s *Solver) removeMarkerEffects(marker symbol, strength float64) {
+	if marker.kind != errorSymbol {
+		return
+	}
+
+	if r, ok := s.rows[marker]; ok {
+		s.objective.insertRow(r, -strength)
+	} else {
+		s.objective.insert(marker, -strength)
+	}
+}
+
+// sortedSymbols keeps pivoting deterministic, which map iteration isn't.
+func sortedSymbols[V any](m map[symbol]V) []symbol {
+	symbols := make([]symbol, 0, len(m))
+	for sym := range m {
+		symbols = append(symbols, sym)
+	}
+	slices.SortFunc(symbols, func(a, b symbol) int {
+		return a.id - b.id
+	})
+	return symbols
+}
+
+func nearZero(value float64) bool {
+	return math.Abs(value) < 1.0e-8
+}
@@ -0,0 +1,81 @@
+package constraint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimpleEquality(t *testing.T) {
+	s := NewSolver()
+	a, b := NewVariable("a"), NewVariable("b")
+
+	require.NoError(t, s.AddConstraint(Equal(a, Constant(10))))
+	require.NoError(t, s.AddConstraint(Equal(a.Plus(Constant(8)), b)))
+	s.UpdateVariables()
+
+	assert.InDelta(t, 10, a.Value(), 1e-9)
+	assert.InDelta(t, 18, b.Value(), 1e-9)
+}
+
+func TestStrengths(t *testing.T) {
+	s := NewSolver()
+	width := NewVariable("width")
+
+	require.NoError(t, s.AddConstraint(GreaterOrEqual(width, Constant(200)).At(Strong)))
+	require.NoError(t, s.AddConstraint(Equal(width, Constant(100)).At(Weak)))
+	s.UpdateVariables()
+	assert.InDelta(t, 200, width.Value(), 1e-9, "the strong minimum should beat the weak preference")
+
+	limit := LessOrEqual(width, Constant(150))
+	require.NoError(t, s.AddConstraint(limit))
+	s.UpdateVariables()
+	assert.InDelta(t, 150, width.Value(), 1e-9, "required constraints beat strong ones")
+
+	require.NoError(t, s.RemoveConstraint(limit))
+	s.UpdateVariables()
+	assert.InDelta(t, 200, width.Value(), 1e-9)
+}
+
+func TestEditVariables(t *testing.T) {
+	s := NewSolver()
+	window, sidebar, content := NewVariable("window"), NewVariable("sidebar"), NewVariable("content")
+
+	require.NoError(t, s.AddEditVariable(window, Strong))
+	require.NoError(t, s.AddConstraint(Equal(sidebar.Plus(content), window)))
+	require.NoError(t, s.AddConstraint(GreaterOrEqual(sidebar, Constant(150))))
+	require.NoError(t, s.AddConstraint(Equal(sidebar, window.Times(0.25)).At(Medium)))
+
+	for _, tt := range []struct{ window, sidebar, content float64 }{
+		{1000, 250, 750},
+		{400, 150, 250},
+		{1200, 300, 900},
+	} {
+		require.NoError(t, s.SuggestValue(window, tt.window))
+		s.UpdateVariables()
+
+		assert.InDelta(t, tt.window, window.Value(), 1e-9)
+		assert.InDelta(t, tt.sidebar, sidebar.Value(), 1e-9)
+		assert.InDelta(t, tt.content, content.Value(), 1e-9)
+	}
+
+	require.NoError(t, s.RemoveEditVariable(window))
+	assert.ErrorIs(t, s.SuggestValue(window, 10), ErrUnknownEditVariable)
+}
+
+func TestSolverErrors(t *testing.T) {
+	s := NewSolver()
+	a := NewVariable("a")
+
+	c := Equal(a, Constant(1))
+	require.NoError(t, s.AddConstraint(c))
+
+	assert.ErrorIs(t, s.AddConstraint(c), ErrDuplicateConstraint)
+	assert.ErrorIs(t, s.AddConstraint(Equal(a, Constant(2))), ErrUnsatisfiableConstraint)
+	assert.ErrorIs(t, s.RemoveConstraint(Equal(a, Constant(1))), ErrUnknownConstraint)
+	assert.ErrorIs(t, s.AddEditVariable(a, Required), ErrRequiredEditVariable)
+
+	require.NoError(t, s.AddEditVariable(a, Weak))
+	assert.ErrorIs(t, s.AddEditVariable(a, Weak), ErrDuplicateEditVariable)
+}
@@ -85,7 +85,10 @@ type Eventable interface {
 	setEvent(*Event)
 }
 
-func (g *gesturer) trigger(reactionType ReactionType, data Eventable) {
+// Trigger sends data to the reactions registered for reactionType, deepest
+// first, until one stops propagation. Update calls it for real input; it can
+// also be called with made up events, e.g. in tests.
+func (g *gesturer) Trigger(reactionType ReactionType, data Eventable) {
 	event := &Event{}
 
 	if reactions, ok := g.events[reactionType]; ok {
@@ -133,21 +136,22 @@ func (g *gesturer) Update() {
 
 	// Keydown events
 	for _, key := range inpututil.AppendJustPressedKeys(nil) {
-		g.trigger(KeyDown, &KeyDownEvent{
+		g.Trigger(KeyDown, &KeyDownEvent{
 			Key: key,
 		})
 	}
 
 	if x != g.MouseX || y != g.MouseY {
-		g.trigger(MouseMoved, &MouseMovedEvent{X: x, Y: y})
+		g.Trigger(MouseMoved, &MouseMovedEvent{X: x, Y: y})
 	}
 
 	g.MouseX = x
 	g.MouseY = y
 
-	_, yoff := ebiten.Wheel()
-	if yoff != 0 {
-		g.trigger(MouseWheel, &MouseWheelEvent{
+	xoff, yoff := ebiten.Wheel()
+	if xoff != 0 || yoff != 0 {
+		g.Trigger(MouseWheel, &MouseWheelEvent{
+			XOffset: xoff,
 			YOffset: yoff,
 			OriginX: x,
 			OriginY: y,
@@ -173,7 +177,7 @@ func (g *gesturer) Update() {
 				TimeStart: time.Now(),
 				Button:    pressedMouseButton,
 			}
-			g.trigger(MouseDown, &MouseDownEvent{
+			g.Trigger(MouseDown, &MouseDownEvent{
 				X:      x,
 				Y:      y,
 				Button: g.Press.Button,
@@ -182,7 +186,7 @@ func (g *gesturer) Update() {
 
 		if time.Since(g.Press.TimeStart) > 100*time.Millisecond || (math.Abs(float64(g.Press.StartX-x)) > 25 || math.Abs(float64(g.Press.StartY-y)) > 25) {
 			if g.Press.X != x || g.Press.Y != y {
-				g.trigger(MouseDrag, &OnMouseDragEvent{
+				g.Trigger(MouseDrag, &OnMouseDragEvent{
 					StartX:    g.Press.X,
 					StartY:    g.Press.Y,
 					X:         x,
@@ -201,7 +205,7 @@ func (g *gesturer) Update() {
 	} else {
 		if g.Press != nil {
 			if time.Since(g.Press.TimeStart) < 100*time.Millisecond || (math.Abs(float64(g.Press.StartX-g.Press.X)) < 8 && math.Abs(float64(g.Press.StartY-g.Press.Y)) < 8) {
-				g.trigger(MouseUp, &MouseUpEvent{
+				g.Trigger(MouseUp, &MouseUpEvent{
 					X:      g.Press.X,
 					Y:      g.Press.Y,
 					Button: g.Press.Button,
@@ -134,7 +134,9 @@ const MouseWheel ReactionType = "MouseWheel"
 
 type MouseWheelEvent struct {
 	OriginX, OriginY int
-	YOffset          float64
+	// Sideways scrolling, e.g. from a trackpad or a tilting wheel.
+	XOffset float64
+	YOffset float64
 	*Event
 }
 
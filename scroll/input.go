@@ -0,0 +1,186 @@
+package scroll
+
+import (
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/reaction"
+)
+
+// Below this speed, in pixels a second, gliding content comes to a stop.
+const minimumVelocity = 5
+
+func (s *Scroll) inputReactions() []reaction.Reaction {
+	reactions := []reaction.Reaction{
+		reaction.NewMouseWheelReaction(
+			doodad.MouseIsWithin[*reaction.MouseWheelEvent](s),
+			func(event *reaction.MouseWheelEvent) {
+				dx, dy := -event.XOffset*s.step(), -event.YOffset*s.step()
+				if !s.scrolls(Vertical) && dx == 0 {
+					// The wheel scrolls whichever way the content does.
+					dx, dy = dy, 0
+				}
+
+				s.stop()
+				// Scrolling that goes nowhere is passed on, so a scroll
+				// around this one takes over at the ends.
+				if s.scrollTo(s.offsetX+dx, s.offsetY+dy) {
+					event.StopPropagation()
+				}
+			},
+		),
+	}
+
+	if s.Config.Drag {
+		reactions = append(reactions, reaction.NewMouseDragReaction(
+			doodad.MouseIsWithin[*reaction.OnMouseDragEvent](s),
+			func(event *reaction.OnMouseDragEvent) {
+				s.drag(float64(event.StartX-event.X), float64(event.StartY-event.Y), event.Button)
+				event.StopPropagation()
+			},
+		))
+	}
+
+	if s.Config.Keyboard {
+		reactions = append(reactions, reaction.NewKeyDownReaction(
+			func(event *reaction.KeyDownEvent) bool {
+				x, y := s.Gesturer().CurrentMouseLocation()
+				return s.IsVisible() && doodad.PointIsWithin(s, x, y)
+			},
+			func(event *reaction.KeyDownEvent) {
+				if s.key(event.Key) {
+					event.StopPropagation()
+				}
+			},
+		))
+	}
+
+	return reactions
+}
+
+// drag scrolls by dx, dy for content dragged with button, keeping track of
+// how fast it is going for momentum.
+func (s *Scroll) drag(dx, dy float64, button ebiten.MouseButton) {
+	now := time.Now()
+	tick := 1.0 / float64(ebiten.TPS())
+	elapsed := max(now.Sub(s.lastDrag).Seconds(), tick)
+	if !s.dragging || elapsed > 0.1 {
+		elapsed = tick
+		s.velocityX, s.velocityY = 0, 0
+	}
+
+	// Smoothed, so one uneven tick at the end doesn't decide the glide.
+	s.velocityX = 0.8*dx/elapsed + 0.2*s.velocityX
+	s.velocityY = 0.8*dy/elapsed + 0.2*s.velocityY
+
+	s.dragging = true
+	s.dragButton = button
+	s.lastDrag = now
+
+	s.scrollTo(s.offsetX+dx, s.offsetY+dy)
+}
+
+// key scrolls for a key press and reports whether it did anything with it.
+func (s *Scroll) key(key ebiten.Key) bool {
+	x, y := s.offsetX, s.offsetY
+	maxX, maxY := s.maxOffset()
+	view := s.Box.Frame()
+
+	// Paging works on the vertical axis, or the horizontal one when that's
+	// the only one that scrolls. A page keeps a step of what was in view.
+	page, length, end := &y, view.Height, maxY
+	if !s.scrolls(Vertical) {
+		page, length, end = &x, view.Width, maxX
+	}
+	length = math.Max(length-s.step(), s.step())
+
+	switch key {
+	case ebiten.KeyArrowUp:
+		y -= s.step()
+	case ebiten.KeyArrowDown:
+		y += s.step()
+	case ebiten.KeyArrowLeft:
+		x -= s.step()
+	case ebiten.KeyArrowRight:
+		x += s.step()
+	case ebiten.KeyPageUp:
+		*page -= length
+	case ebiten.KeyPageDown:
+		*page += length
+	case ebiten.KeyHome:
+		*page = 0
+	case ebiten.KeyEnd:
+		*page = end
+	default:
+		return false
+	}
+
+	s.stop()
+	return s.scrollTo(x, y)
+}
+
+// stop ends any momentum.
+func (s *Scroll) stop() {
+	s.velocityX, s.velocityY = 0, 0
+	s.dragging = false
+}
+
+func (s *Scroll) friction() float64 {
+	if s.Config.Friction == 0 {
+		return 0.95
+	}
+	return s.Config.Friction
+}
+
+// Update glides the content on after a drag, and keeps the offset within the
+// content when it shrinks or the scroll grows.
+func (s *Scroll) Update() error {
+	now := time.Now()
+	elapsed := math.Min(now.Sub(s.lastUpdate).Seconds(), 0.1)
+	s.lastUpdate = now
+
+	maxX, maxY := s.maxOffset()
+	if s.offsetX > maxX || s.offsetY > maxY {
+		s.scrollTo(s.offsetX, s.offsetY)
+	}
+
+	if s.dragging {
+		if ebiten.IsMouseButtonPressed(s.dragButton) {
+			return nil
+		}
+
+		// Let go. Content held still before being let go doesn't glide.
+		s.dragging = false
+		if !s.Config.Momentum || time.Since(s.lastDrag) > 100*time.Millisecond {
+			s.stop()
+		}
+	}
+
+	if s.velocityX == 0 && s.velocityY == 0 || !s.IsVisible() {
+		return nil
+	}
+
+	s.glide(elapsed)
+	return nil
+}
+
+// glide moves the content on by elapsed seconds of momentum, and slows it
+// down by the friction.
+func (s *Scroll) glide(elapsed float64) {
+	x, y := s.offsetX, s.offsetY
+	s.scrollTo(x+s.velocityX*elapsed, y+s.velocityY*elapsed)
+
+	decay := math.Pow(1-s.friction(), elapsed)
+	s.velocityX *= decay
+	s.velocityY *= decay
+
+	// Stop at the ends, or once it has slowed to a crawl.
+	if s.offsetX == x || math.Abs(s.velocityX) < minimumVelocity {
+		s.velocityX = 0
+	}
+	if s.offsetY == y || math.Abs(s.velocityY) < minimumVelocity {
+		s.velocityY = 0
+	}
+}
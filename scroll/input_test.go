@@ -0,0 +1,167 @@
+package scroll
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/reaction"
+	"github.com/jhuggett/thingamabob/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKey(t *testing.T) {
+	// A 200x100 scroll over 400x300 of content, with steps of 40. A page is
+	// the view less a step.
+	tests := []struct {
+		name    string
+		axes    Axes
+		fromX   float64
+		fromY   float64
+		key     ebiten.Key
+		handled bool
+		expectX float64
+		expectY float64
+	}{
+		{"arrow down", Vertical, 0, 0, ebiten.KeyArrowDown, true, 0, 40},
+		{"arrow up at the start", Vertical, 0, 0, ebiten.KeyArrowUp, false, 0, 0},
+		{"arrow right", Both, 0, 0, ebiten.KeyArrowRight, true, 40, 0},
+		{"arrow right without scrolling that way", Vertical, 0, 0, ebiten.KeyArrowRight, false, 0, 0},
+		{"page down", Vertical, 0, 0, ebiten.KeyPageDown, true, 0, 60},
+		{"page up", Vertical, 0, 100, ebiten.KeyPageUp, true, 0, 40},
+		{"page down near the end", Vertical, 0, 180, ebiten.KeyPageDown, true, 0, 200},
+		{"page down at the end", Vertical, 0, 200, ebiten.KeyPageDown, false, 0, 200},
+		{"home", Both, 50, 100, ebiten.KeyHome, true, 50, 0},
+		{"end", Both, 50, 100, ebiten.KeyEnd, true, 50, 200},
+		{"page down horizontally", Horizontal, 0, 0, ebiten.KeyPageDown, true, 160, 0},
+		{"end horizontally", Horizontal, 0, 0, ebiten.KeyEnd, true, 200, 0},
+		{"home horizontally", Horizontal, 120, 0, ebiten.KeyHome, true, 0, 0},
+		{"other keys", Vertical, 0, 0, ebiten.KeyA, false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := scrollOver(Config{Axes: tt.axes}, 400, 300)
+			s.ScrollTo(tt.fromX, tt.fromY)
+
+			assert.Equal(t, tt.handled, s.key(tt.key))
+			x, y := s.Offset()
+			assert.Equal(t, tt.expectX, x)
+			assert.Equal(t, tt.expectY, y)
+		})
+	}
+
+	t.Run("pages by at least a step", func(t *testing.T) {
+		s, _ := scrollOver(Config{Step: 80}, 400, 300)
+		s.key(ebiten.KeyPageDown)
+		_, y := s.Offset()
+		assert.Equal(t, 80.0, y)
+	})
+}
+
+func TestGlide(t *testing.T) {
+	// Gliding for a tenth of a second keeps 0.05^0.1 of the speed with the
+	// default friction, 0.5^0.1 with a friction of 0.5.
+	slowed := math.Pow(0.05, 0.1)
+
+	tests := []struct {
+		name      string
+		friction  float64
+		fromY     float64
+		velocityX float64
+		velocityY float64
+		y         float64
+		expectX   float64
+		expectY   float64
+	}{
+		{"moves and slows down", 0, 0, 0, 1000, 100, 0, 1000 * slowed},
+		{"moves back", 0, 150, 0, -500, 100, 0, -500 * slowed},
+		{"more friction", 0.5, 0, 0, 1000, 100, 0, 1000 * math.Pow(0.5, 0.1)},
+		{"stops at the end", 0, 200, 0, 1000, 200, 0, 0},
+		{"is stopped by the end", 0, 190, 0, 1000, 200, 0, 1000 * slowed},
+		{"stops when it slows to a crawl", 0, 0, 0, 6, 0.6, 0, 0},
+		{"stops on an axis that doesn't scroll", 0, 0, 1000, 1000, 100, 0, 1000 * slowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := scrollOver(Config{Friction: tt.friction}, 400, 300)
+			s.ScrollTo(0, tt.fromY)
+			s.velocityX, s.velocityY = tt.velocityX, tt.velocityY
+
+			s.glide(0.1)
+			_, y := s.Offset()
+			assert.InDelta(t, tt.y, y, 1e-9)
+			assert.InDelta(t, tt.expectX, s.velocityX, 1e-9)
+			assert.InDelta(t, tt.expectY, s.velocityY, 1e-9)
+		})
+	}
+}
+
+func TestNestedInput(t *testing.T) {
+	// A 200x100 scroll over a stack of a 200x100 scroll, over 200x300 of
+	// content, and 200x200 more below it.
+	nested := func(config Config) (r *doodadtest.Root, outer, inner *Scroll, content *doodad.Default) {
+		r = doodadtest.NewRoot(200, 100)
+		outer = New(config)
+		r.AddChild(outer)
+
+		column := stack.New(stack.Config{})
+		outer.AddChild(column)
+		inner = New(config)
+		inner.SetLayout(box.Computed(func(b *box.Box) {
+			b.SetDimensions(200, 100)
+		}))
+		column.AddChild(inner, doodadtest.Fixed(200, 200))
+		content = doodadtest.Fixed(200, 300)
+		inner.AddChild(content)
+
+		doodad.Setup(outer)
+		r.Layout().Recalculate()
+		return r, outer, inner, content
+	}
+
+	t.Run("the inner scroll takes the wheel until its end", func(t *testing.T) {
+		r, outer, inner, _ := nested(Config{})
+		wheel := func() {
+			r.Trigger(reaction.MouseWheel, &reaction.MouseWheelEvent{OriginX: 10, OriginY: 10, YOffset: -1})
+		}
+
+		wheel()
+		_, innerY := inner.Offset()
+		_, outerY := outer.Offset()
+		assert.Equal(t, 40.0, innerY)
+		assert.Equal(t, 0.0, outerY)
+
+		for range 5 {
+			wheel()
+		}
+		_, innerY = inner.Offset()
+		_, outerY = outer.Offset()
+		assert.Equal(t, 200.0, innerY)
+		assert.Equal(t, 40.0, outerY, "the outer scroll takes over at the inner one's end")
+	})
+
+	t.Run("content that takes a drag gets it first", func(t *testing.T) {
+		r, outer, inner, content := nested(Config{Drag: true})
+		dragged := false
+		content.Reactions().Add(reaction.NewMouseDragReaction(
+			doodad.MouseIsWithin[*reaction.OnMouseDragEvent](content),
+			func(event *reaction.OnMouseDragEvent) {
+				dragged = true
+				event.StopPropagation()
+			},
+		))
+		content.Reactions().Register(content.Gesturer(), content.Z())
+
+		r.Trigger(reaction.MouseDrag, &reaction.OnMouseDragEvent{StartX: 10, StartY: 50, X: 10, Y: 10})
+		_, innerY := inner.Offset()
+		_, outerY := outer.Offset()
+		assert.True(t, dragged)
+		assert.Equal(t, 0.0, innerY)
+		assert.Equal(t, 0.0, outerY)
+	})
+}
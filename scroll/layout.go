@@ -0,0 +1,109 @@
+package scroll
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// slot is where child goes, inside its margin, in a scroll occupying frame.
+// Its lengths are the scroll's, which the child keeps on axes that don't
+// scroll.
+func (s *Scroll) slot(child doodad.Doodad, frame box.Frame) box.Frame {
+	slot := frame.Inset(doodad.Margin(child))
+	slot.X -= s.offsetX
+	slot.Y -= s.offsetY
+	return slot
+}
+
+// sizeChild gives frame the lengths of slot on the axes that don't scroll.
+func (s *Scroll) sizeChild(frame box.Frame, slot box.Frame) box.Frame {
+	if !s.scrolls(Horizontal) {
+		frame.Width = slot.Width
+	}
+	if !s.scrolls(Vertical) {
+		frame.Height = slot.Height
+	}
+	return frame
+}
+
+// childConstraints leave the content as much room as it wants on the axes
+// that scroll, and width or height on the others.
+func (s *Scroll) childConstraints(width, height int) doodad.Constraints {
+	constraints := doodad.UnboundedConstraints()
+	if !s.scrolls(Horizontal) {
+		constraints.MaxWidth = width
+	}
+	if !s.scrolls(Vertical) {
+		constraints.MaxHeight = height
+	}
+	return constraints
+}
+
+func (s *Scroll) thickness() int {
+	if s.Config.ScrollbarThickness == 0 {
+		return 8
+	}
+	return s.Config.ScrollbarThickness
+}
+
+// scrollbarFrame is where the scrollbar for axis goes: along the right edge
+// for vertical scrolling, the left for right to left content, and along the
+// bottom for horizontal. When both axes scroll the corner is left out of both.
+func (s *Scroll) scrollbarFrame(axis Axes) box.Frame {
+	frame := s.Box.Frame()
+	thickness := float64(s.thickness())
+
+	corner := 0.0
+	if s.axes() == Both {
+		corner = thickness
+	}
+	rtl := s.Direction() == config.DirectionRightToLeft
+
+	if axis == Vertical {
+		bar := box.Frame{X: frame.X + frame.Width - thickness, Y: frame.Y, Width: thickness, Height: max(frame.Height-corner, 0)}
+		if rtl {
+			bar.X = frame.X
+		}
+		return bar
+	}
+
+	bar := box.Frame{X: frame.X, Y: frame.Y + frame.Height - thickness, Width: max(frame.Width-corner, 0), Height: thickness}
+	if rtl {
+		bar.X += corner
+	}
+	return bar
+}
+
+// Measure asks for the size of the content, which the scroll then limits to
+// whatever room it is given, like Fill does for other containers.
+func (s *Scroll) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	size := doodad.Rectangle{}
+	for _, child := range s.content() {
+		measured := doodad.MeasureWithMargin(child, s.childConstraints(constraints.MaxWidth, constraints.MaxHeight))
+		size.Width = max(size.Width, measured.Width)
+		size.Height = max(size.Height, measured.Height)
+	}
+
+	if constraints.MaxWidth != doodad.Unbounded {
+		size.Width = constraints.MaxWidth
+	}
+	if constraints.MaxHeight != doodad.Unbounded {
+		size.Height = constraints.MaxHeight
+	}
+
+	return constraints.Constrain(size)
+}
+
+func (s *Scroll) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(s, rect)
+
+	frame := rect.Frame()
+	constraints := s.childConstraints(rect.Width, rect.Height)
+	for _, child := range s.content() {
+		size := doodad.Measure(child, constraints.Deflate(doodad.Margin(child)))
+		slot := s.slot(child, frame)
+		slot = s.sizeChild(box.Frame{X: slot.X, Y: slot.Y, Width: float64(size.Width), Height: float64(size.Height)}, slot)
+		doodad.Arrange(child, slot.Rect())
+	}
+}
@@ -0,0 +1,291 @@
+package scroll
+
+import (
+	"image/color"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/reaction"
+)
+
+// Axes are the directions content can scroll in.
+type Axes int
+
+const (
+	Vertical Axes = 1 << iota
+	Horizontal
+	Both = Vertical | Horizontal
+)
+
+type Config struct {
+	// Zero scrolls vertically.
+	Axes Axes
+
+	// How far a notch of the mouse wheel, or an arrow key, scrolls. Zero uses
+	// 40.
+	Step float64
+
+	// Drag scrolls by dragging the content itself, as well as the
+	// scrollbars.
+	Drag bool
+	// Keyboard scrolls with the arrow keys, page up and down, home and end
+	// while the mouse is over the container.
+	Keyboard bool
+	// Momentum keeps content that is let go of mid drag gliding until
+	// Friction stops it.
+	Momentum bool
+	// The fraction of its speed gliding content loses every second. Zero
+	// uses 0.95.
+	Friction float64
+
+	// Scrollbars are drawn over the content, along the right and bottom
+	// edges, for each axis that has more content than fits. They can be
+	// dragged, and clicking the track scrolls by a page.
+	Scrollbars bool
+	// Zero uses 8.
+	ScrollbarThickness int
+	// Zero uses a translucent gray.
+	ScrollbarColor color.Color
+	// Nil doesn't draw the track.
+	TrackColor color.Color
+}
+
+func New(config Config) *Scroll {
+	return &Scroll{
+		Config: config,
+	}
+}
+
+// Scroll clips its children to its layout and moves them as it scrolls. The
+// children are all placed at the top left, so usually there is just one, e.g.
+// a stack. On axes that don't scroll they are given the scroll's length.
+type Scroll struct {
+	Config Config
+
+	doodad.Default
+
+	bars []*scrollbar
+
+	// Reactions for scrolling, registered under the content so anything in
+	// it, e.g. a scroll inside this one or something draggable, gets input
+	// first. Wheel scrolling that goes nowhere is passed back to this one.
+	input *reaction.Reactions
+
+	offsetX, offsetY float64
+
+	// Momentum, in pixels a second.
+	velocityX, velocityY float64
+	dragging             bool
+	dragButton           ebiten.MouseButton
+	lastDrag             time.Time
+	lastUpdate           time.Time
+}
+
+// Names of the calculation steps a scroll owns.
+const (
+	positionStep  = "scroll.position"
+	sizeChildStep = "scroll.size-child"
+	scrollbarStep = "scroll.scrollbar"
+)
+
+func (s *Scroll) Setup() {
+	s.SetClipsChildren(true)
+
+	content := s.content()
+	for i, child := range content {
+		doodad.Restack(child, s.depth(1+i))
+
+		child.Layout().NamedComputed(positionStep, func(b *box.Box) {
+			slot := s.slot(child, s.Box.Frame())
+			b.SetFrameOrigin(slot.X, slot.Y)
+		})
+	}
+
+	if s.Config.Scrollbars && s.bars == nil {
+		for _, axis := range []Axes{Vertical, Horizontal} {
+			if !s.scrolls(axis) {
+				continue
+			}
+
+			bar := &scrollbar{scroll: s, axis: axis}
+			s.AddChild(bar)
+			doodad.Restack(bar, s.depth(1+len(content)+len(s.bars)))
+			bar.Layout().NamedComputed(scrollbarStep, func(b *box.Box) {
+				b.SetFrame(s.scrollbarFrame(axis))
+			})
+			s.bars = append(s.bars, bar)
+		}
+	}
+
+	s.Children().Setup()
+
+	// Added after the children's own steps so the lengths the scroll gives
+	// them win.
+	for _, child := range content {
+		child.Layout().NamedComputed(sizeChildStep, func(b *box.Box) {
+			b.SetFrame(s.sizeChild(b.Frame(), s.slot(child, s.Box.Frame())))
+		})
+	}
+
+	s.input = &reaction.Reactions{}
+	s.input.SetResource(s)
+	s.input.Add(s.inputReactions()...)
+	s.input.Register(s.Gesturer(), s.depth(0))
+
+	s.DoOnTeardown(func() {
+		s.input.Unregister()
+		for _, bar := range s.bars {
+			if err := s.Children().Remove(bar); err != nil {
+				slog.Warn("Failed to remove scrollbar", "error", err)
+			}
+		}
+		s.bars = nil
+		s.stop()
+	})
+}
+
+// depth is the depth of the child at index: the input reactions first, then
+// the content, then each of the scrollbars.
+func (s *Scroll) depth(index int) []int {
+	return append(append([]int{}, s.Z()...), index)
+}
+
+// content is the children that scroll, which leaves out the scrollbars.
+func (s *Scroll) content() []doodad.Doodad {
+	content := []doodad.Doodad{}
+	for _, child := range s.Children().Doodads {
+		if _, ok := child.(*scrollbar); !ok {
+			content = append(content, child)
+		}
+	}
+	return content
+}
+
+func (s *Scroll) axes() Axes {
+	if s.Config.Axes == 0 {
+		return Vertical
+	}
+	return s.Config.Axes
+}
+
+func (s *Scroll) scrolls(axis Axes) bool {
+	return s.axes()&axis != 0
+}
+
+func (s *Scroll) step() float64 {
+	if s.Config.Step == 0 {
+		return 40
+	}
+	return s.Config.Step
+}
+
+// Offset is how far the content is scrolled from its top left.
+func (s *Scroll) Offset() (x, y float64) {
+	return s.offsetX, s.offsetY
+}
+
+// ScrollTo scrolls so x, y of the content is at the top left, as far as the
+// content allows. It stops any momentum.
+func (s *Scroll) ScrollTo(x, y float64) {
+	s.stop()
+	s.scrollTo(x, y)
+}
+
+// ScrollBy scrolls the content by dx, dy, as far as it allows. It stops any
+// momentum.
+func (s *Scroll) ScrollBy(dx, dy float64) {
+	s.ScrollTo(s.offsetX+dx, s.offsetY+dy)
+}
+
+// ScrollToChild scrolls as little as it takes to bring child, any doodad
+// inside the scroll, and its margin into view. Children bigger than the
+// scroll are lined up with its top left.
+func (s *Scroll) ScrollToChild(child doodad.Doodad) {
+	if child.Layout() == nil {
+		return
+	}
+
+	target := child.Layout().MarginFrame()
+	view := s.Box.Frame()
+
+	s.ScrollBy(
+		reveal(target.X, target.Width, view.X, view.Width),
+		reveal(target.Y, target.Height, view.Y, view.Height),
+	)
+}
+
+// reveal is how far to scroll along an axis to bring the span from start
+// into the view, as little as possible, or to line it up with the start of
+// the view if it doesn't fit.
+func reveal(start, length, viewStart, viewLength float64) float64 {
+	switch {
+	case start < viewStart || length > viewLength:
+		return start - viewStart
+	case start+length > viewStart+viewLength:
+		return start + length - (viewStart + viewLength)
+	default:
+		return 0
+	}
+}
+
+// scrollTo moves the content, clamping the offset to what it allows, and
+// reports whether it moved.
+func (s *Scroll) scrollTo(x, y float64) bool {
+	maxX, maxY := s.maxOffset()
+	x = math.Max(0, math.Min(x, maxX))
+	y = math.Max(0, math.Min(y, maxY))
+
+	if x == s.offsetX && y == s.offsetY {
+		return false
+	}
+	s.offsetX, s.offsetY = x, y
+
+	box.Batch(func() {
+		for _, child := range s.content() {
+			child.Layout().FlagNeedsRecalculation()
+		}
+	})
+	return true
+}
+
+// contentSize is the size of the biggest child, with its margin.
+func (s *Scroll) contentSize() (width, height float64) {
+	for _, child := range s.content() {
+		if child.IsCollapsed() || child.Layout() == nil {
+			continue
+		}
+
+		frame := child.Layout().MarginFrame()
+		width = math.Max(width, frame.Width)
+		height = math.Max(height, frame.Height)
+	}
+	return width, height
+}
+
+// maxOffset is how far the content can scroll on each axis, zero on axes
+// that don't scroll.
+func (s *Scroll) maxOffset() (x, y float64) {
+	width, height := s.contentSize()
+	view := s.Box.Frame()
+
+	if s.scrolls(Horizontal) {
+		x = math.Max(width-view.Width, 0)
+	}
+	if s.scrolls(Vertical) {
+		y = math.Max(height-view.Height, 0)
+	}
+	return x, y
+}
+
+// overflows is true when there is more content on axis than fits.
+func (s *Scroll) overflows(axis Axes) bool {
+	x, y := s.maxOffset()
+	if axis == Horizontal {
+		return x > 0
+	}
+	return y > 0
+}
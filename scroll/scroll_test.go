@@ -0,0 +1,166 @@
+package scroll
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/jhuggett/thingamabob/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+// scrollOver is a set up 200x100 scroll over content of the given size.
+func scrollOver(config Config, width, height int) (*Scroll, *doodad.Default) {
+	r := doodadtest.NewRoot(200, 100)
+	s := New(config)
+	r.AddChild(s)
+	content := doodadtest.Fixed(width, height)
+	s.AddChild(content)
+
+	doodad.Setup(s)
+	r.Layout().Recalculate()
+	return s, content
+}
+
+func barsOf(s *Scroll) []*scrollbar {
+	bars := []*scrollbar{}
+	for _, child := range s.Children().Doodads {
+		if bar, ok := child.(*scrollbar); ok {
+			bars = append(bars, bar)
+		}
+	}
+	return bars
+}
+
+func TestScrollbars(t *testing.T) {
+	t.Run("one for each axis that scrolls", func(t *testing.T) {
+		for axes, expected := range map[Axes]int{Vertical: 1, Horizontal: 1, Both: 2} {
+			s, _ := scrollOver(Config{Axes: axes, Scrollbars: true}, 400, 300)
+			assert.Len(t, barsOf(s), expected)
+		}
+	})
+
+	t.Run("each above the content at its own depth", func(t *testing.T) {
+		s, content := scrollOver(Config{Axes: Both, Scrollbars: true}, 400, 300)
+		bars := barsOf(s)
+
+		assert.Equal(t, s.depth(1), content.Z())
+		assert.Equal(t, s.depth(2), bars[0].Z())
+		assert.Equal(t, s.depth(3), bars[1].Z())
+	})
+
+	t.Run("set up again after a teardown", func(t *testing.T) {
+		s, _ := scrollOver(Config{Axes: Both, Scrollbars: true}, 400, 300)
+		parent := s.Parent()
+		layout := s.Layout()
+
+		assert.NoError(t, s.Teardown())
+		assert.Empty(t, barsOf(s))
+
+		s.SetLayout(layout)
+		s.AddChild(doodadtest.Fixed(400, 300))
+		doodad.Setup(s)
+		parent.Layout().Recalculate()
+		assert.Len(t, barsOf(s), 2)
+	})
+
+	t.Run("in the corners", func(t *testing.T) {
+		s, _ := scrollOver(Config{Axes: Both, Scrollbars: true, ScrollbarThickness: 10}, 400, 300)
+		assert.Equal(t, box.Frame{X: 190, Width: 10, Height: 90}, s.scrollbarFrame(Vertical))
+		assert.Equal(t, box.Frame{Y: 90, Width: 190, Height: 10}, s.scrollbarFrame(Horizontal))
+	})
+}
+
+func TestMaxOffset(t *testing.T) {
+	tests := []struct {
+		name          string
+		axes          Axes
+		width, height int
+		x, y          float64
+	}{
+		{"vertical", Vertical, 400, 300, 0, 200},
+		{"horizontal", Horizontal, 400, 300, 200, 0},
+		{"both", Both, 400, 300, 200, 200},
+		{"content that fits", Both, 150, 80, 0, 0},
+		{"content that fits exactly", Both, 200, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := scrollOver(Config{Axes: tt.axes}, tt.width, tt.height)
+			x, y := s.maxOffset()
+			assert.Equal(t, tt.x, x)
+			assert.Equal(t, tt.y, y)
+		})
+	}
+
+	t.Run("with the content's margin", func(t *testing.T) {
+		s, content := scrollOver(Config{Axes: Both}, 400, 300)
+		content.Layout().SetMargin(config.EqualPadding(10))
+		x, y := s.maxOffset()
+		assert.Equal(t, 220.0, x)
+		assert.Equal(t, 220.0, y)
+	})
+}
+
+func TestReveal(t *testing.T) {
+	tests := []struct {
+		name                  string
+		start, length         float64
+		viewStart, viewLength float64
+		expected              float64
+	}{
+		{"in view", 20, 10, 0, 100, 0},
+		{"filling the view", 0, 100, 0, 100, 0},
+		{"before the view", -30, 10, 0, 100, -30},
+		{"partly before the view", -5, 10, 0, 100, -5},
+		{"after the view", 120, 10, 0, 100, 30},
+		{"partly after the view", 95, 10, 0, 100, 5},
+		{"bigger than the view", 50, 200, 0, 100, 50},
+		{"in a moved view", 250, 10, 200, 100, 0},
+		{"after a moved view", 310, 10, 200, 100, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, reveal(tt.start, tt.length, tt.viewStart, tt.viewLength))
+		})
+	}
+}
+
+func TestScrollToChild(t *testing.T) {
+	r := doodadtest.NewRoot(200, 100)
+	s := New(Config{})
+	r.AddChild(s)
+	content := stack.New(stack.Config{Flow: config.TopToBottom})
+	s.AddChild(content)
+	lines := []*doodad.Default{}
+	for i := 0; i < 10; i++ {
+		lines = append(lines, doodadtest.Fixed(200, 30))
+		content.AddChild(lines[i])
+	}
+	doodad.Setup(s)
+	r.Layout().Recalculate()
+
+	tests := []struct {
+		name     string
+		line     int
+		expected float64
+	}{
+		{"down to a line below", 4, 50},
+		{"down to the next line", 5, 80},
+		{"up to a line above", 1, 30},
+		{"not for a line in view", 2, 30},
+	}
+
+	// Each case starts where the one before it left off.
+	for _, tt := range tests {
+		s.ScrollToChild(lines[tt.line])
+		r.Layout().Recalculate()
+
+		_, y := s.Offset()
+		assert.Equal(t, tt.expected, y, tt.name)
+	}
+}
@@ -0,0 +1,166 @@
+package scroll
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/paint"
+	"github.com/jhuggett/thingamabob/reaction"
+)
+
+var defaultScrollbarColor = color.NRGBA{R: 128, G: 128, B: 128, A: 160}
+
+// scrollbar shows how much of the content is in view along an axis, and how
+// far it is scrolled. Dragging the thumb scrolls, and clicking the track on
+// either side of it scrolls by a page. It is only drawn, and only reacts,
+// while the content overflows.
+type scrollbar struct {
+	doodad.Default
+
+	scroll *Scroll
+	axis   Axes
+
+	thumb *paint.Painter
+	track *paint.Painter
+}
+
+func (b *scrollbar) Setup() {
+	radius := paint.Radius(float64(b.scroll.thickness()) / 2)
+
+	thumbColor := b.scroll.Config.ScrollbarColor
+	if thumbColor == nil {
+		thumbColor = defaultScrollbarColor
+	}
+	b.thumb = paint.NewPainter(paint.Style{Color: thumbColor, Radius: radius})
+	b.track = paint.NewPainter(paint.Style{Color: b.scroll.Config.TrackColor, Radius: radius})
+
+	b.SetCachedDraw(&doodad.CachedDraw{
+		Override: func(_ doodad.CachedDraw, screen *ebiten.Image) {
+			b.draw(screen)
+		},
+	})
+
+	b.Reactions().Add(
+		reaction.NewMouseDragReaction(
+			overBar[*reaction.OnMouseDragEvent](b),
+			func(event *reaction.OnMouseDragEvent) {
+				moved := b.along(float64(event.X-event.StartX), float64(event.Y-event.StartY))
+				b.scrollBy(moved * b.ratio())
+				event.StopPropagation()
+			},
+		),
+		reaction.NewMouseUpReaction(
+			overBar[*reaction.MouseUpEvent](b),
+			func(event *reaction.MouseUpEvent) {
+				frame := b.Layout().Frame()
+				clicked := b.along(float64(event.X)-frame.X, float64(event.Y)-frame.Y)
+
+				start, length := b.thumbSpan()
+				page := b.viewLength() - b.scroll.step()
+				switch {
+				case clicked < start:
+					b.scrollBy(-page)
+				case clicked > start+length:
+					b.scrollBy(page)
+				}
+				event.StopPropagation()
+			},
+		),
+	)
+}
+
+// overBar is true for events over the bar while it is in use.
+func overBar[T reaction.PositionedEvent](b *scrollbar) func(event T) bool {
+	within := doodad.MouseIsWithin[T](b)
+	return func(event T) bool {
+		return b.scroll.overflows(b.axis) && within(event)
+	}
+}
+
+// along picks the length along the bar out of x and y.
+func (b *scrollbar) along(x, y float64) float64 {
+	if b.axis == Horizontal {
+		return x
+	}
+	return y
+}
+
+func (b *scrollbar) scrollBy(delta float64) {
+	b.scroll.stop()
+	if b.axis == Horizontal {
+		b.scroll.scrollTo(b.scroll.offsetX+delta, b.scroll.offsetY)
+	} else {
+		b.scroll.scrollTo(b.scroll.offsetX, b.scroll.offsetY+delta)
+	}
+}
+
+func (b *scrollbar) viewLength() float64 {
+	view := b.scroll.Box.Frame()
+	return b.along(view.Width, view.Height)
+}
+
+func (b *scrollbar) trackLength() float64 {
+	frame := b.Layout().Frame()
+	return b.along(frame.Width, frame.Height)
+}
+
+// thumbSpan is where the thumb starts along the track and how long it is. It
+// is as long, relative to the track, as the view is to the content, but never
+// shorter than twice the bar's thickness.
+func (b *scrollbar) thumbSpan() (start, length float64) {
+	track := b.trackLength()
+	width, height := b.scroll.contentSize()
+	content := b.along(width, height)
+	offset := b.along(b.scroll.offsetX, b.scroll.offsetY)
+	view := b.viewLength()
+
+	if content <= view {
+		return 0, track
+	}
+
+	length = math.Min(math.Max(track*view/content, float64(2*b.scroll.thickness())), track)
+	return (track - length) * offset / (content - view), length
+}
+
+// ratio is how far the content scrolls for each pixel the thumb is dragged.
+func (b *scrollbar) ratio() float64 {
+	_, length := b.thumbSpan()
+	free := b.trackLength() - length
+	if free <= 0 {
+		return 0
+	}
+
+	x, y := b.scroll.maxOffset()
+	return b.along(x, y) / free
+}
+
+func (b *scrollbar) draw(screen *ebiten.Image) {
+	if !b.scroll.overflows(b.axis) {
+		return
+	}
+
+	frame := b.Layout().Frame()
+	rect := frame.Rect()
+
+	if track := b.track.Paint(rect.Width, rect.Height); track != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(float64(rect.X), float64(rect.Y))
+		screen.DrawImage(track, op)
+	}
+
+	start, length := b.thumbSpan()
+	x, y := frame.X, frame.Y+start
+	width, height := frame.Width, length
+	if b.axis == Horizontal {
+		x, y = frame.X+start, frame.Y
+		width, height = length, frame.Height
+	}
+
+	if thumb := b.thumb.Paint(int(math.Round(width)), int(math.Round(height))); thumb != nil {
+		op := &ebiten.DrawImageOptions{}
+		op.GeoM.Translate(math.Round(x), math.Round(y))
+		screen.DrawImage(thumb, op)
+	}
+}
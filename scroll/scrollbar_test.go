@@ -0,0 +1,66 @@
+package scroll
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbSpan(t *testing.T) {
+	// The vertical bar of a 200x100 scroll runs the whole 100 high track,
+	// and the thumb is at least 16, twice the bar's thickness, long.
+	tests := []struct {
+		name          string
+		height        int
+		offset        float64
+		start, length float64
+	}{
+		{"at the start", 300, 0, 0, 100.0 / 3},
+		{"half way", 300, 100, 100.0 / 3, 100.0 / 3},
+		{"at the end", 300, 200, 200.0 / 3, 100.0 / 3},
+		{"content that fits", 80, 0, 0, 100},
+		{"content that fits exactly", 100, 0, 0, 100},
+		{"never shorter than twice the thickness", 10000, 0, 0, 16},
+		{"never shorter at the end", 10000, 9900, 84, 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := scrollOver(Config{Scrollbars: true}, 200, tt.height)
+			s.ScrollTo(0, tt.offset)
+
+			start, length := barsOf(s)[0].thumbSpan()
+			assert.InDelta(t, tt.start, start, 1e-9)
+			assert.InDelta(t, tt.length, length, 1e-9)
+		})
+	}
+
+	t.Run("horizontal", func(t *testing.T) {
+		s, _ := scrollOver(Config{Axes: Horizontal, Scrollbars: true}, 800, 100)
+		s.ScrollTo(300, 0)
+
+		start, length := barsOf(s)[0].thumbSpan()
+		assert.InDelta(t, 75, start, 1e-9)
+		assert.InDelta(t, 50, length, 1e-9)
+	})
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		height   int
+		expected float64
+	}{
+		// 200 to scroll over the 200/3 the thumb can move.
+		{"in proportion", 300, 3},
+		{"with the shortest thumb", 10000, 9900.0 / 84},
+		{"content that fits", 80, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := scrollOver(Config{Scrollbars: true}, 200, tt.height)
+			assert.InDelta(t, tt.expected, barsOf(s)[0].ratio(), 1e-9)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package stack
+
+import (
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// Flex controls how a child's length along the flow changes when the stack
+// has more or less room than its children want. Lengths are only shared out
+// when the stack's size is decided from outside, e.g. with Fill.
+type Flex struct {
+	// How much of any free space the child takes, relative to the other
+	// children's Grow.
+	Grow float64
+	// How much the child gives up when there isn't enough space, relative to
+	// the other children's Shrink, weighted by their basis.
+	Shrink float64
+
+	// The child's length before free space is shared out. Zero uses its
+	// measured length unless FixedBasis is set. Children that don't
+	// implement doodad.Measurer are measured by their current size, which
+	// includes any growing, so they should use a fixed basis.
+	Basis      int
+	FixedBasis bool
+
+	// Bounds on the child's length along the flow. Zero means no maximum.
+	// Like Basis, they don't include the child's margin.
+	Min int
+	Max int
+}
+
+// Share is a flex for a child that takes weight shares of the stack's length,
+// e.g. Share(2) and Share(1) split it 2:1 regardless of content.
+func Share(weight float64) Flex {
+	return Flex{Grow: weight, Shrink: weight, FixedBasis: true}
+}
+
+// SetFlex sets how child grows and shrinks. Call it before Setup.
+func (s *Stack) SetFlex(child doodad.Doodad, flex Flex) {
+	if s.flex == nil {
+		s.flex = map[doodad.Doodad]Flex{}
+	}
+	s.flex[child] = flex
+}
+
+func (s *Stack) Flex(child doodad.Doodad) (Flex, bool) {
+	flex, ok := s.flex[child]
+	return flex, ok
+}
+
+func (f Flex) clamp(length float64) float64 {
+	if f.Max > 0 {
+		length = min(length, float64(f.Max))
+	}
+	return max(length, float64(f.Min))
+}
+
+// basis replaces each flexing child's length along the flow with its basis.
+func (s *Stack) basis(sizes []doodad.Rectangle) {
+	for i, child := range s.Children().Doodads {
+		flex, ok := s.flex[child]
+		if !ok || child.IsCollapsed() {
+			continue
+		}
+
+		margin := s.marginLength(child)
+		main, _ := s.axes(sizes[i])
+		if flex.FixedBasis {
+			main = flex.Basis + margin
+		}
+		main = int(flex.clamp(float64(main-margin))) + margin
+
+		if s.Config.Flow.IsHorizontal() {
+			sizes[i].Width = main
+		} else {
+			sizes[i].Height = main
+		}
+	}
+}
+
+// flexLengths shares the difference between length and the line's length out
+// between its children, returning each child's length along the flow.
+// Children that hit their Min or Max are frozen there and the rest is shared
+// again between the others.
+func (s *Stack) flexLengths(l line, sizes []doodad.Rectangle, length float64) []float64 {
+	children := s.Children().Doodads[l.first:l.end]
+
+	lengths := make([]float64, len(children))
+	bases := make([]float64, len(children))
+	frozen := make([]bool, len(children))
+	for i, child := range children {
+		main, _ := s.axes(sizes[l.first+i])
+		bases[i] = float64(main)
+		lengths[i] = bases[i]
+
+		// Collapsed children stay at nothing.
+		frozen[i] = child.IsCollapsed()
+	}
+
+	growing := length > float64(l.main)
+	for {
+		free := length - float64(s.Config.SpaceBetween*(l.count-1))
+		total := 0.0
+		for i, child := range children {
+			if frozen[i] {
+				free -= lengths[i]
+				continue
+			}
+			free -= bases[i]
+
+			flex := s.flex[child]
+			if growing {
+				total += flex.Grow
+			} else {
+				total += flex.Shrink * bases[i]
+			}
+		}
+		if total <= 0 || free == 0 {
+			return lengths
+		}
+
+		clamped := false
+		for i, child := range children {
+			if frozen[i] {
+				continue
+			}
+
+			flex := s.flex[child]
+			share := flex.Shrink * bases[i] / total
+			if growing {
+				share = flex.Grow / total
+			}
+
+			lengths[i] = bases[i] + free*share
+			margin := float64(s.marginLength(child))
+			if target := flex.clamp(max(lengths[i]-margin, 0)) + margin; target != lengths[i] {
+				lengths[i] = target
+				frozen[i] = true
+				clamped = true
+			}
+		}
+		if !clamped {
+			return lengths
+		}
+	}
+}
+
+// resize gives a slot a new length along the flow.
+func (s *Stack) resize(slot box.Frame, length float64) box.Frame {
+	if s.Config.Flow.IsHorizontal() {
+		slot.Width = length
+	} else {
+		slot.Height = length
+	}
+	return slot
+}
+
+// marginLength is how much of child's length along the flow is margin.
+func (s *Stack) marginLength(child doodad.Doodad) int {
+	margin := doodad.Margin(child)
+	if s.Config.Flow.IsHorizontal() {
+		return margin.Left + margin.Right
+	}
+	return margin.Top + margin.Bottom
+}
+
+// stretches is true when children fill the stack's cross axis.
+func (s *Stack) stretches() bool {
+	if s.Config.Flow.IsHorizontal() {
+		return s.Config.VerticalAlignment == config.VerticalAlignmentStretch
+	}
+	return s.Config.HorizontalAlignment == config.HorizontalAlignmentStretch
+}
+
+// sizeChild gives frame the lengths from slot that the stack decides for
+// child: along the flow if it flexes, across it if the stack stretches.
+func (s *Stack) sizeChild(child doodad.Doodad, frame box.Frame, slot box.Frame) box.Frame {
+	horizontal := s.Config.Flow.IsHorizontal()
+	_, flexes := s.flex[child]
+
+	if (flexes && horizontal) || (s.stretches() && !horizontal) {
+		frame.Width = slot.Width
+	}
+	if (flexes && !horizontal) || (s.stretches() && horizontal) {
+		frame.Height = slot.Height
+	}
+	return frame
+}
@@ -0,0 +1,91 @@
+package stack
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlexLengths(t *testing.T) {
+	tests := []struct {
+		name         string
+		spaceBetween int
+		sizes        []doodad.Rectangle
+		// Children without a flex don't grow or shrink.
+		flex      map[int]Flex
+		collapsed []int
+		length    float64
+		expected  []float64
+	}{
+		{"no flex", 0, widths(30, 40), nil, nil, 100, []float64{30, 40}},
+		{"grows by weight", 0, widths(0, 0), map[int]Flex{0: Share(1), 1: Share(2)}, nil, 90, []float64{30, 60}},
+		{"grows around fixed children", 10, widths(20, 30), map[int]Flex{1: {Grow: 1}}, nil, 100, []float64{20, 70}},
+		{"shrinks by weight and basis", 0, widths(100, 50), map[int]Flex{0: {Shrink: 1}, 1: {Shrink: 1}}, nil, 120, []float64{80, 40}},
+		{"shrinks no further than zero", 10, widths(50, 50), map[int]Flex{0: {Shrink: 1}, 1: {Shrink: 1}}, nil, 0, []float64{0, 0}},
+		{"one shrinks to zero, the other takes the rest", 0, widths(10, 100), map[int]Flex{0: {Shrink: 10}, 1: {Shrink: 1}}, nil, 50, []float64{0, 50}},
+		{"max is shared out again", 0, widths(0, 0), map[int]Flex{0: {Grow: 1, Max: 20}, 1: {Grow: 1}}, nil, 100, []float64{20, 80}},
+		{"min holds while shrinking", 0, widths(50, 50), map[int]Flex{0: {Shrink: 1, Min: 45}, 1: {Shrink: 1}}, nil, 60, []float64{45, 15}},
+		{"exact fit", 0, widths(30, 70), map[int]Flex{0: Share(1), 1: Share(1)}, nil, 100, []float64{30, 70}},
+		{"collapsed stay at nothing", 10, widths(0, 0, 0), map[int]Flex{0: Share(1), 1: Share(1), 2: Share(1)}, []int{1}, 110, []float64{50, 0, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stackWith(Config{Flow: config.LeftToRight, SpaceBetween: tt.spaceBetween}, len(tt.sizes))
+			for i, flex := range tt.flex {
+				s.SetFlex(s.Children().Doodads[i], flex)
+			}
+			for _, i := range tt.collapsed {
+				s.Children().Doodads[i].Collapse()
+			}
+
+			l := s.lines(tt.sizes, math.Inf(1))[0]
+			assert.InDeltaSlice(t, tt.expected, s.flexLengths(l, tt.sizes, tt.length), 1e-9)
+		})
+	}
+}
+
+func TestBasis(t *testing.T) {
+	tests := []struct {
+		name     string
+		flow     config.Flow
+		flex     Flex
+		margin   config.Padding
+		measured doodad.Rectangle
+		expected doodad.Rectangle
+	}{
+		{"measured", config.LeftToRight, Flex{Grow: 1}, config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 60, Height: 10}},
+		{"fixed", config.LeftToRight, Flex{Basis: 20, FixedBasis: true}, config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 20, Height: 10}},
+		{"fixed at zero", config.LeftToRight, Share(1), config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 0, Height: 10}},
+		{"vertical", config.TopToBottom, Flex{Basis: 20, FixedBasis: true}, config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 60, Height: 20}},
+		{"clamped to max", config.LeftToRight, Flex{Max: 40}, config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 40, Height: 10}},
+		{"clamped to min", config.LeftToRight, Flex{Min: 80}, config.Padding{}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 80, Height: 10}},
+		{"keeps the margin", config.LeftToRight, Flex{Basis: 20, FixedBasis: true, Max: 15}, config.Padding{Left: 4, Right: 6}, doodad.Rectangle{Width: 60, Height: 10}, doodad.Rectangle{Width: 25, Height: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stackWith(Config{Flow: tt.flow}, 1)
+			child := s.Children().Doodads[0]
+			child.Layout().SetMargin(tt.margin)
+			s.SetFlex(child, tt.flex)
+
+			sizes := []doodad.Rectangle{tt.measured}
+			s.basis(sizes)
+			assert.Equal(t, tt.expected, sizes[0])
+		})
+	}
+
+	t.Run("skips collapsed children", func(t *testing.T) {
+		s := stackWith(Config{Flow: config.LeftToRight}, 1)
+		s.SetFlex(s.Children().Doodads[0], Flex{Basis: 20, FixedBasis: true})
+		s.Children().Doodads[0].Collapse()
+
+		sizes := []doodad.Rectangle{{}}
+		s.basis(sizes)
+		assert.Equal(t, doodad.Rectangle{}, sizes[0])
+	})
+}
@@ -0,0 +1,339 @@
+package stack
+
+import (
+	"math"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/position/box"
+)
+
+// measureChildren measures every child, with its margin, in the space left
+// inside the padding. Flexing children get their basis along the flow instead.
+func (s *Stack) measureChildren(constraints doodad.Constraints) []doodad.Rectangle {
+	inner := constraints.Deflate(s.Config.Padding).Loosen()
+
+	sizes := make([]doodad.Rectangle, len(s.Children().Doodads))
+	for i, child := range s.Children().Doodads {
+		sizes[i] = doodad.MeasureWithMargin(child, inner)
+	}
+	s.basis(sizes)
+	return sizes
+}
+
+// line is a run of children laid out one after the other along the flow.
+// Without wrapping there is only ever one.
+type line struct {
+	// The children in the line are [first, end).
+	first, end int
+	// How many of them aren't collapsed.
+	count int
+
+	// Length along the flow, including the space between children, and the
+	// thickness of the thickest child.
+	main, cross int
+}
+
+// lines breaks the children into lines no longer than length, measured along
+// the flow. A child longer than length still gets a line of its own. Collapsed
+// children go in whichever line they fall in without taking up any of it.
+func (s *Stack) lines(sizes []doodad.Rectangle, length float64) []line {
+	lines := []line{}
+	current := line{}
+	for i, size := range sizes {
+		current.end = i + 1
+		if s.collapsed(i) {
+			continue
+		}
+
+		main, cross := s.axes(size)
+
+		if current.count > 0 && float64(current.main+s.Config.SpaceBetween+main) > length {
+			current.end = i
+			lines = append(lines, current)
+			current = line{first: i, end: i + 1}
+		}
+
+		if current.count > 0 {
+			current.main += s.Config.SpaceBetween
+		}
+		current.main += main
+		current.cross = max(current.cross, cross)
+		current.count++
+	}
+	if len(sizes) > 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// collapsed is true if the child at index has been collapsed out of the
+// layout.
+func (s *Stack) collapsed(index int) bool {
+	return s.Children().Doodads[index].IsCollapsed()
+}
+
+// wrapLength is how long a line can get inside the padding when the stack is
+// given constraints.
+func (s *Stack) wrapLength(constraints doodad.Constraints) float64 {
+	if !s.Config.Wrap {
+		return math.Inf(1)
+	}
+
+	length, padding := constraints.MaxHeight, s.Config.Padding.Top+s.Config.Padding.Bottom
+	if s.Config.Flow.IsHorizontal() {
+		length, padding = constraints.MaxWidth, s.Config.Padding.Left+s.Config.Padding.Right
+	}
+
+	if length == doodad.Unbounded {
+		return math.Inf(1)
+	}
+	return float64(length - padding)
+}
+
+// axes splits a size into its length along the flow and across it.
+func (s *Stack) axes(size doodad.Rectangle) (main, cross int) {
+	if s.Config.Flow.IsHorizontal() {
+		return size.Width, size.Height
+	}
+	return size.Height, size.Width
+}
+
+// contentSize is the size of the children laid out along the flow, wrapping
+// at length, without the stack's padding.
+func (s *Stack) contentSize(sizes []doodad.Rectangle, length float64) doodad.Rectangle {
+	main, cross := 0, 0
+	for i, l := range s.lines(sizes, length) {
+		if i > 0 {
+			cross += s.Config.LineSpacing
+		}
+		main = max(main, l.main)
+		cross += l.cross
+	}
+
+	if s.Config.Flow.IsHorizontal() {
+		return doodad.Rectangle{Width: main, Height: cross}
+	}
+	return doodad.Rectangle{Width: cross, Height: main}
+}
+
+// place works out the frame of each child, given their sizes including their
+// margins, for a stack occupying frame. The frames are inside the margins.
+func (s *Stack) place(frame box.Frame, sizes []doodad.Rectangle) []box.Frame {
+	padding := s.Config.Padding
+	inner := box.Frame{
+		X:      frame.X + float64(padding.Left),
+		Y:      frame.Y + float64(padding.Top),
+		Width:  frame.Width - float64(padding.Left+padding.Right),
+		Height: frame.Height - float64(padding.Top+padding.Bottom),
+	}
+
+	innerMain, innerCross := inner.Height, inner.Width
+	if s.Config.Flow.IsHorizontal() {
+		innerMain, innerCross = inner.Width, inner.Height
+	}
+
+	length := math.Inf(1)
+	if s.Config.Wrap {
+		length = innerMain
+	}
+
+	slots := make([]box.Frame, len(sizes))
+	crossOffset := 0.0
+	for _, l := range s.lines(sizes, length) {
+		// Children are aligned within their line when wrapping, and within
+		// the whole stack when not.
+		lineCross := innerCross
+		if s.Config.Wrap {
+			lineCross = float64(l.cross)
+		}
+
+		lengths := s.flexLengths(l, sizes, innerMain)
+		mainOffset, spacing := s.justify(lengths, l.count, innerMain)
+		for i := l.first; i < l.end; i++ {
+			if s.collapsed(i) {
+				// Left with no size where the next child goes.
+				slot := box.Frame{X: inner.X + crossOffset, Y: inner.Y + mainOffset}
+				if s.Config.Flow.IsHorizontal() {
+					slot.X, slot.Y = inner.X+mainOffset, inner.Y+crossOffset
+				}
+				slots[i] = s.mirror(inner, slot)
+				continue
+			}
+
+			_, cross := s.axes(sizes[i])
+			main := lengths[i-l.first]
+			slot := s.resize(box.Frame{Width: float64(sizes[i].Width), Height: float64(sizes[i].Height)}, main)
+
+			if s.Config.Flow.IsHorizontal() {
+				slot.X = inner.X + mainOffset
+				slot.Y = inner.Y + crossOffset + alignVertically(s.Config.VerticalAlignment, lineCross-float64(cross))
+				if s.stretches() {
+					slot.Height = lineCross
+				}
+			} else {
+				slot.X = inner.X + crossOffset + alignHorizontally(s.Config.HorizontalAlignment, lineCross-float64(cross))
+				slot.Y = inner.Y + mainOffset
+				if s.stretches() {
+					slot.Width = lineCross
+				}
+			}
+
+			mainOffset += main + float64(s.Config.SpaceBetween) + spacing
+			slots[i] = s.mirror(inner, slot).Inset(doodad.Margin(s.Children().Doodads[i]))
+		}
+
+		crossOffset += float64(l.cross + s.Config.LineSpacing)
+	}
+	return slots
+}
+
+// mirror turns a slot placed as if flowing from the top left of inner around
+// to match the stack's flow and direction. Reversed flows are flipped along
+// the flow, right to left content is flipped horizontally, so a right to left
+// flow in right to left content runs left to right.
+func (s *Stack) mirror(inner box.Frame, slot box.Frame) box.Frame {
+	flipX := s.Direction() == config.DirectionRightToLeft
+	if s.Config.Flow.IsReversed() {
+		if s.Config.Flow.IsHorizontal() {
+			flipX = !flipX
+		} else {
+			slot.Y = inner.Y + inner.Height - (slot.Y - inner.Y) - slot.Height
+		}
+	}
+
+	if flipX {
+		slot.X = inner.X + inner.Width - (slot.X - inner.X) - slot.Width
+	}
+	return slot
+}
+
+// justify returns where a line of count children with the given lengths
+// starts along the flow, and the extra space between each of them.
+func (s *Stack) justify(lengths []float64, count int, length float64) (start float64, spacing float64) {
+	if count == 0 {
+		return 0, 0
+	}
+
+	free := length - float64(s.Config.SpaceBetween*(count-1))
+	for _, main := range lengths {
+		free -= main
+	}
+	if free <= 0 || math.IsInf(free, 0) {
+		return 0, 0
+	}
+
+	n := float64(count)
+	switch s.Config.Justification {
+	case config.JustificationCenter:
+		return box.Half(free), 0
+	case config.JustificationEnd:
+		return free, 0
+	case config.JustificationSpaceBetween:
+		if n < 2 {
+			return 0, 0
+		}
+		return 0, free / (n - 1)
+	case config.JustificationSpaceAround:
+		return free / n / 2, free / n
+	case config.JustificationSpaceEvenly:
+		return free / (n + 1), free / (n + 1)
+	default:
+		return 0, 0
+	}
+}
+
+// slot returns the frame for the child at index, placing all of the children
+// again if the stack has moved or been resized since they were last placed.
+func (s *Stack) slot(index int) box.Frame {
+	frame := s.Box.Frame()
+	children := s.Children().Doodads
+
+	// A filling stack offers its children its own size, so they are measured
+	// again whenever it changes.
+	if len(s.measured) != len(children) || (s.Config.LayoutRule == Fill && s.slotsFrame != frame) {
+		s.measured = s.measureChildren(s.childConstraints(frame.Rect()))
+		s.slots = nil
+	}
+
+	if s.slots == nil || s.slotsFrame != frame {
+		s.slots = s.place(frame, s.measured)
+		s.slotsFrame = frame
+	}
+
+	return s.slots[index]
+}
+
+// childConstraints is the space the stack offers its children when it
+// occupies rect. Only a filling stack offers rect, so it should be the stack's
+// final rect, not one its steps are still working out.
+func (s *Stack) childConstraints(rect box.Rect) doodad.Constraints {
+	if s.Config.LayoutRule == Fill {
+		return doodad.Loose(rect.Width, rect.Height)
+	}
+	return s.fitConstraints()
+}
+
+// fitConstraints is the space a stack fitting its contents offers its
+// children: as much as they want, up to the maximum size of its layout.
+func (s *Stack) fitConstraints() doodad.Constraints {
+	constraints := doodad.UnboundedConstraints()
+	limits := s.Box.Limits()
+	if limits.MaxWidth > 0 {
+		constraints.MaxWidth = limits.MaxWidth
+	}
+	if limits.MaxHeight > 0 {
+		constraints.MaxHeight = limits.MaxHeight
+	}
+	return constraints
+}
+
+func alignVertically(alignment config.VerticalAlignment, free float64) float64 {
+	switch alignment {
+	case config.VerticalAlignmentCenter:
+		return box.Half(free)
+	case config.VerticalAlignmentBottom:
+		return free
+	default:
+		return 0
+	}
+}
+
+func alignHorizontally(alignment config.HorizontalAlignment, free float64) float64 {
+	switch alignment {
+	case config.HorizontalAlignmentCenter:
+		return box.Half(free)
+	case config.HorizontalAlignmentRight:
+		return free
+	default:
+		return 0
+	}
+}
+
+func (s *Stack) Measure(constraints doodad.Constraints) doodad.Rectangle {
+	content := s.contentSize(s.measureChildren(constraints), s.wrapLength(constraints))
+	size := doodad.Rectangle{
+		Width:  content.Width + s.Config.Padding.Left + s.Config.Padding.Right,
+		Height: content.Height + s.Config.Padding.Top + s.Config.Padding.Bottom,
+	}
+
+	if s.Config.LayoutRule == Fill {
+		if constraints.MaxWidth != doodad.Unbounded {
+			size.Width = constraints.MaxWidth
+		}
+		if constraints.MaxHeight != doodad.Unbounded {
+			size.Height = constraints.MaxHeight
+		}
+	}
+
+	return constraints.Constrain(size)
+}
+
+func (s *Stack) Arrange(rect box.Rect) {
+	doodad.ArrangeLayout(s, rect)
+
+	sizes := s.measureChildren(doodad.Loose(rect.Width, rect.Height))
+	for i, slot := range s.place(rect.Frame(), sizes) {
+		doodad.Arrange(s.Children().Doodads[i], slot.Rect())
+	}
+}
@@ -0,0 +1,155 @@
+package stack
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/stretchr/testify/assert"
+)
+
+// stackWith is a stack with n children, for testing the layout math on its
+// own. The children's sizes come from the sizes passed in, not their layouts.
+func stackWith(config Config, n int) *Stack {
+	r := doodadtest.NewRoot(800, 600)
+	s := New(config)
+	r.AddChild(s)
+	for i := 0; i < n; i++ {
+		s.AddChild(doodadtest.Fixed(0, 0))
+	}
+	return s
+}
+
+func widths(widths ...int) []doodad.Rectangle {
+	sizes := make([]doodad.Rectangle, len(widths))
+	for i, width := range widths {
+		sizes[i] = doodad.Rectangle{Width: width, Height: 10}
+	}
+	return sizes
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		sizes     []doodad.Rectangle
+		collapsed []int
+		length    float64
+		expected  []line
+	}{
+		{"no children", nil, nil, 100, []line{}},
+		{"unbounded", widths(30, 30, 30), nil, math.Inf(1), []line{{0, 3, 3, 110, 10}}},
+		{"fits exactly", widths(30, 30, 30), nil, 110, []line{{0, 3, 3, 110, 10}}},
+		{"one short of fitting", widths(30, 30, 30), nil, 109, []line{{0, 2, 2, 70, 10}, {2, 3, 1, 30, 10}}},
+		{"too long for any line", widths(30, 200, 30), nil, 100, []line{{0, 1, 1, 30, 10}, {1, 2, 1, 200, 10}, {2, 3, 1, 30, 10}}},
+		{"first child too long", widths(200, 30), nil, 100, []line{{0, 1, 1, 200, 10}, {1, 2, 1, 30, 10}}},
+		{"collapsed take no room", widths(30, 30, 30), []int{1}, 70, []line{{0, 3, 2, 70, 10}}},
+		{"all collapsed", widths(30, 30), []int{0, 1}, 10, []line{{0, 2, 0, 0, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stackWith(Config{Flow: config.LeftToRight, SpaceBetween: 10, Wrap: true}, len(tt.sizes))
+			for _, i := range tt.collapsed {
+				s.Children().Doodads[i].Collapse()
+			}
+			assert.Equal(t, tt.expected, s.lines(tt.sizes, tt.length))
+		})
+	}
+}
+
+func TestWrapLength(t *testing.T) {
+	padding := config.Padding{Top: 1, Right: 2, Bottom: 3, Left: 4}
+	tests := []struct {
+		name        string
+		config      Config
+		constraints doodad.Constraints
+		expected    float64
+	}{
+		{"not wrapping", Config{Flow: config.LeftToRight, Padding: padding}, doodad.Loose(100, 50), math.Inf(1)},
+		{"unbounded", Config{Flow: config.LeftToRight, Padding: padding, Wrap: true}, doodad.UnboundedConstraints(), math.Inf(1)},
+		{"horizontal", Config{Flow: config.LeftToRight, Padding: padding, Wrap: true}, doodad.Loose(100, 50), 94},
+		{"reversed horizontal", Config{Flow: config.RightToLeft, Padding: padding, Wrap: true}, doodad.Loose(100, 50), 94},
+		{"vertical", Config{Flow: config.TopToBottom, Padding: padding, Wrap: true}, doodad.Loose(100, 50), 46},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, stackWith(tt.config, 0).wrapLength(tt.constraints))
+		})
+	}
+}
+
+func TestContentSizeWraps(t *testing.T) {
+	s := stackWith(Config{Flow: config.LeftToRight, SpaceBetween: 10, LineSpacing: 5, Wrap: true}, 3)
+	sizes := []doodad.Rectangle{{Width: 30, Height: 10}, {Width: 30, Height: 20}, {Width: 50, Height: 15}}
+
+	assert.Equal(t, doodad.Rectangle{Width: 130, Height: 20}, s.contentSize(sizes, 130))
+	assert.Equal(t, doodad.Rectangle{Width: 70, Height: 40}, s.contentSize(sizes, 129))
+}
+
+func TestJustify(t *testing.T) {
+	tests := []struct {
+		name          string
+		justification config.Justification
+		lengths       []float64
+		length        float64
+		start         float64
+		spacing       float64
+	}{
+		{"start", config.JustificationStart, []float64{20, 30}, 100, 0, 0},
+		{"center", config.JustificationCenter, []float64{20, 30}, 100, 20, 0},
+		{"end", config.JustificationEnd, []float64{20, 30}, 100, 40, 0},
+		{"space between", config.JustificationSpaceBetween, []float64{20, 30, 10}, 100, 0, 10},
+		{"space between one child", config.JustificationSpaceBetween, []float64{20}, 100, 0, 0},
+		{"space around", config.JustificationSpaceAround, []float64{20, 30}, 100, 10, 20},
+		{"space evenly", config.JustificationSpaceEvenly, []float64{20, 30}, 100, 40.0 / 3, 40.0 / 3},
+		{"no room to spare", config.JustificationEnd, []float64{50, 50}, 100, 0, 0},
+		{"overflowing", config.JustificationCenter, []float64{80, 50}, 100, 0, 0},
+		{"unbounded", config.JustificationCenter, []float64{20, 30}, math.Inf(1), 0, 0},
+		{"no children", config.JustificationCenter, nil, 100, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stackWith(Config{Flow: config.LeftToRight, SpaceBetween: 10, Justification: tt.justification}, 0)
+			start, spacing := s.justify(tt.lengths, len(tt.lengths), tt.length)
+			assert.InDelta(t, tt.start, start, 1e-9)
+			assert.InDelta(t, tt.spacing, spacing, 1e-9)
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	inner := box.Frame{X: 10, Y: 20, Width: 100, Height: 50}
+	slot := box.Frame{X: 15, Y: 25, Width: 30, Height: 10}
+
+	tests := []struct {
+		name      string
+		flow      config.Flow
+		direction config.Direction
+		expected  box.Frame
+	}{
+		{"top to bottom", config.TopToBottom, config.DirectionLeftToRight, slot},
+		{"left to right", config.LeftToRight, config.DirectionLeftToRight, slot},
+		{"right to left", config.RightToLeft, config.DirectionLeftToRight, box.Frame{X: 75, Y: 25, Width: 30, Height: 10}},
+		{"bottom to top", config.BottomToTop, config.DirectionLeftToRight, box.Frame{X: 15, Y: 55, Width: 30, Height: 10}},
+		{"top to bottom, right to left content", config.TopToBottom, config.DirectionRightToLeft, box.Frame{X: 75, Y: 25, Width: 30, Height: 10}},
+		{"left to right, right to left content", config.LeftToRight, config.DirectionRightToLeft, box.Frame{X: 75, Y: 25, Width: 30, Height: 10}},
+		{"right to left, right to left content", config.RightToLeft, config.DirectionRightToLeft, slot},
+		{"bottom to top, right to left content", config.BottomToTop, config.DirectionRightToLeft, box.Frame{X: 75, Y: 55, Width: 30, Height: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := stackWith(Config{Flow: tt.flow}, 0)
+			s.SetDirection(tt.direction)
+
+			mirrored := s.mirror(inner, slot)
+			assert.Equal(t, tt.expected, mirrored)
+			assert.Equal(t, slot, s.mirror(inner, mirrored), "mirroring twice gives the slot back")
+		})
+	}
+}
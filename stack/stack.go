@@ -6,6 +6,8 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/jhuggett/thingamabob/config"
 	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/effect"
+	"github.com/jhuggett/thingamabob/paint"
 	"github.com/jhuggett/thingamabob/position/box"
 	"github.com/jhuggett/thingamabob/reaction"
 
@@ -24,6 +26,14 @@ type Config struct {
 	SpaceBetween int
 	Padding      config.Padding
 
+	// Wrap starts a new row (or column, when flowing top to bottom) when the
+	// next child would run past the end of the stack. A stack that fits its
+	// contents wraps at the maximum size of its layout, see box.Limits, or at
+	// the space its parent offers it.
+	Wrap bool
+	// The space between rows or columns when wrapping.
+	LineSpacing int
+
 	BackgroundColor color.Color
 
 	LayoutRule LayoutRule
@@ -31,8 +41,23 @@ type Config struct {
 	HorizontalAlignment config.HorizontalAlignment
 	VerticalAlignment   config.VerticalAlignment
 
+	// How children are spread along the flow when the stack is longer than
+	// they need, e.g. with Fill. Spacing is added to SpaceBetween. Each line
+	// is justified on its own when wrapping.
+	Justification config.Justification
+
 	Border config.Border
 
+	// The background and border, painted with anti-aliased vector paths.
+	// Allows gradient and image backgrounds, rounded corners, and a
+	// different color and width for each side of the border. BackgroundColor
+	// and Border are shorthands for its Color and Border, and are used when
+	// those aren't set.
+	Background paint.Style
+
+	// Deprecated: use SetEffects, which works on any doodad. A shader set
+	// here is run as an effect given the uniforms Cursor, the mouse relative
+	// to the stack, Radius (100) and Strength (0.6).
 	Shader *ebiten.Shader
 }
 
@@ -48,84 +73,40 @@ type Stack struct {
 	Config Config
 
 	doodad.Default
+
+	// Child sizes from the last measure, and where they were placed.
+	measured   []doodad.Rectangle
+	slots      []box.Frame
+	slotsFrame box.Frame
+
+	flex map[doodad.Doodad]Flex
+
+	painter *paint.Painter
 }
 
+// Names of the calculation steps a stack owns. Re-running Setup replaces
+// them instead of adding more.
+const (
+	positionStep  = "stack.position"
+	sizeChildStep = "stack.size-child"
+	sizeStep      = "stack.size"
+)
+
 /*
   - Warning: when setup is called, we reposition all children!!!
-    This means that if you want to apply changes to the child of a stack, you
-    should call setup first, otherwise the changes will be discarded.
+    A child's stack position step is replaced in place, so steps you add to
+    a child after the first setup still run after it and are kept.
 */
 func (s *Stack) Setup() {
-	var previousChild doodad.Doodad
-	for _, child := range s.Children().Doodads {
-		previousChildReferenceCopy := previousChild
-
-		switch s.Config.Flow {
-		case config.LeftToRight:
-			if previousChildReferenceCopy == nil {
-				child.Layout().Computed(func(b *box.Box) {
-					switch s.Config.VerticalAlignment {
-					case config.VerticalAlignmentTop:
-						b.SetY(s.Box.Y() + s.Config.Padding.Top)
-					case config.VerticalAlignmentCenter:
-						b.SetY(s.Box.Y() + (s.Box.Height() / 2) - (b.Height() / 2))
-					case config.VerticalAlignmentBottom:
-						b.SetY(s.Box.Y() + s.Box.Height() - b.Height() - s.Config.Padding.Bottom)
-					}
-
-					b.SetX(s.Box.X() + s.Config.Padding.Left)
-				})
-			} else {
-				child.Layout().Computed(func(b *box.Box) {
-					switch s.Config.VerticalAlignment {
-					case config.VerticalAlignmentTop:
-						b.SetY(s.Box.Y() + s.Config.Padding.Top)
-					case config.VerticalAlignmentCenter:
-						b.SetY(s.Box.Y() + (s.Box.Height() / 2) - (b.Height() / 2))
-					case config.VerticalAlignmentBottom:
-						b.SetY(s.Box.Y() + s.Box.Height() - b.Height() - s.Config.Padding.Bottom)
-					}
-
-					b.SetX(previousChildReferenceCopy.Layout().X() +
-						previousChildReferenceCopy.Layout().Width() +
-						s.Config.SpaceBetween)
-				})
-			}
-		case config.TopToBottom:
-			if previousChildReferenceCopy == nil {
-				child.Layout().Computed(func(b *box.Box) {
-					switch s.Config.HorizontalAlignment {
-					case config.HorizontalAlignmentLeft:
-						b.SetX(s.Box.X() + s.Config.Padding.Left)
-					case config.HorizontalAlignmentCenter:
-						b.SetX(s.Box.X() + (s.Box.Width() / 2) - (b.Width() / 2))
-					case config.HorizontalAlignmentRight:
-						b.SetX(s.Box.X() + s.Box.Width() - b.Width() - s.Config.Padding.Right)
-					}
-
-					b.SetY(s.Box.Y() + s.Config.Padding.Top)
-				})
-			} else {
-				child.Layout().Computed(func(b *box.Box) {
-					switch s.Config.HorizontalAlignment {
-					case config.HorizontalAlignmentLeft:
-						b.SetX(s.Box.X() + s.Config.Padding.Left)
-					case config.HorizontalAlignmentCenter:
-						b.SetX(s.Box.X() + (s.Box.Width() / 2) - (b.Width() / 2))
-					case config.HorizontalAlignmentRight:
-						b.SetX(s.Box.X() + s.Box.Width() - b.Width() - s.Config.Padding.Right)
-					}
-
-					b.SetY(previousChildReferenceCopy.Layout().Y() +
-						previousChildReferenceCopy.Layout().Height() +
-						s.Config.SpaceBetween)
-				})
-			}
-		}
-		previousChild = child
-
+	for i, child := range s.Children().Doodads {
+		child.Layout().NamedComputed(positionStep, func(b *box.Box) {
+			slot := s.slot(i)
+			b.SetFrameOrigin(slot.X, slot.Y)
+		})
 	}
 
+	// Drags and the wheel aren't stopped, so a scroll around the stack still
+	// gets them.
 	s.Reactions().Add(
 		reaction.NewMouseMovedReaction(
 			doodad.MouseIsWithin[*reaction.MouseMovedEvent](s),
@@ -144,107 +125,88 @@ func (s *Stack) Setup() {
 				event.StopPropagation()
 			},
 		),
-		reaction.NewMouseDragReaction(
-			doodad.MouseIsWithin[*reaction.OnMouseDragEvent](s),
-			func(event *reaction.OnMouseDragEvent) {
-				event.StopPropagation()
-			},
-		),
-		reaction.NewMouseWheelReaction(
-			doodad.MouseIsWithin[*reaction.MouseWheelEvent](s),
-			func(event *reaction.MouseWheelEvent) {
-				event.StopPropagation()
-			},
-		),
 	)
 	s.Children().Setup()
 
-	s.Box.Computed(func(b *box.Box) {
+	// Added after the children's own steps so the size the stack gives them
+	// wins.
+	for i, child := range s.Children().Doodads {
+		if _, ok := s.flex[child]; !ok && !s.stretches() {
+			continue
+		}
+		child.Layout().NamedComputed(sizeChildStep, func(b *box.Box) {
+			b.SetFrame(s.sizeChild(child, b.Frame(), s.slot(i)))
+		})
+	}
+
+	s.Box.NamedComputed(sizeStep, func(b *box.Box) {
+		s.measured = nil
+		s.slots = nil
+
 		switch s.Config.LayoutRule {
 		case FitContents:
-
-			bounding := box.Bounding(s.Children().Boxes())
-			b.SetWidth(bounding.Width() + s.Config.Padding.Left + s.Config.Padding.Right)
-			b.SetHeight(bounding.Height() + s.Config.Padding.Top + s.Config.Padding.Bottom)
+			constraints := s.fitConstraints()
+			s.measured = s.measureChildren(constraints)
+			content := s.contentSize(s.measured, s.wrapLength(constraints))
+			b.SetWidth(content.Width + s.Config.Padding.Left + s.Config.Padding.Right)
+			b.SetHeight(content.Height + s.Config.Padding.Top + s.Config.Padding.Bottom)
 
 		case Fill:
-			// Do nothing, we fill the available space
+			// Do nothing, we fill the available space. That space may be set
+			// by steps that run after this one, so the children are measured
+			// in it once the box is done, when they are placed.
 		}
 	})
 
-	if (s.Config.BackgroundColor != nil || s.Config.Border.Exists()) && s.Box.Width() > 0 && s.Box.Height() > 0 {
-		background := ebiten.NewImage(s.Box.Width(), s.Box.Height())
-		if s.Config.BackgroundColor != nil {
-			background.Fill(s.Config.BackgroundColor)
-		} else {
-			background.Fill(color.RGBA{0, 0, 0, 0})
-		}
-
-		// Draw the border if specified
-		if s.Config.Border.Left > 0 {
-			for x := 0; x < s.Config.Border.Left; x++ {
-				for y := 0; y < s.Box.Height(); y++ {
-					background.Set(x, y, s.Config.Border.Color)
-				}
-			}
-		}
-		if s.Config.Border.Right > 0 {
-			for x := s.Box.Width() - s.Config.Border.Right; x < s.Box.Width(); x++ {
-				for y := 0; y < s.Box.Height(); y++ {
-					background.Set(x, y, s.Config.Border.Color)
-				}
-			}
-		}
-		if s.Config.Border.Top > 0 {
-			for y := 0; y < s.Config.Border.Top; y++ {
-				for x := 0; x < s.Box.Width(); x++ {
-					background.Set(x, y, s.Config.Border.Color)
+	if s.Config.Shader != nil {
+		s.SetEffects(effect.Effect{
+			Shader: s.Config.Shader,
+			Uniforms: func(ctx effect.Context) map[string]any {
+				return map[string]any{
+					"Cursor":   []float32{float32(ctx.MouseX), float32(ctx.MouseY)},
+					"Radius":   float32(100),
+					"Strength": float32(0.6),
 				}
-			}
-		}
-		if s.Config.Border.Bottom > 0 {
-			for y := s.Box.Height() - s.Config.Border.Bottom; y < s.Box.Height(); y++ {
-				for x := 0; x < s.Box.Width(); x++ {
-					background.Set(x, y, s.Config.Border.Color)
-				}
-			}
-		}
+			},
+		})
+	}
 
-		time := 0.0
-
-		if s.Config.Shader != nil {
-			s.SetCachedDraw(&doodad.CachedDraw{
-				Image: background,
-				Override: func(cachedDraw doodad.CachedDraw, screen *ebiten.Image) {
-					x, y := s.Layout().XY()
-
-					if s.Config.Shader != nil {
-
-						mx, my := s.Gesturer().CurrentMouseLocation()
-
-						mx -= x
-						my -= y / 2
-
-						time += 0.016
-
-						opts := &ebiten.DrawRectShaderOptions{
-							Uniforms: map[string]any{
-								"Cursor":   []float32{float32(mx), float32(my)},
-								"Radius":   float32(100),
-								"Strength": float32(0.6),
-							},
-						}
-						opts.GeoM.Translate(float64(x), float64(y))
-						opts.Images[0] = background
-
-						screen.DrawRectShader(s.Box.Width(), s.Box.Height(), s.Config.Shader, opts)
-					}
-				},
-			})
-		} else {
-			s.SetCachedDraw(&doodad.CachedDraw{
-				Image: background,
-			})
+	s.painter = paint.NewPainter(s.backgroundStyle())
+	s.drawBackground()
+
+	s.DoOnTeardown(s.Box.OnChange(func(old, new box.Rect) {
+		if old.Width != new.Width || old.Height != new.Height {
+			s.drawBackground()
 		}
+	}))
+}
+
+// backgroundStyle is Config.Background with the shorthands filled in.
+func (s *Stack) backgroundStyle() paint.Style {
+	style := s.Config.Background
+	if style.Color == nil {
+		style.Color = s.Config.BackgroundColor
 	}
+	if !style.Border.Exists() && s.Config.Border.Exists() {
+		style.Border = paint.BorderFrom(s.Config.Border)
+	}
+	return style
+}
+
+// drawBackground renders the background and border into the cached draw at
+// the stack's current size.
+func (s *Stack) drawBackground() {
+	if s.painter.Style().IsZero() {
+		return
+	}
+
+	background := s.painter.Paint(s.Box.Width(), s.Box.Height())
+	if background == nil {
+		s.SetCachedDraw()
+		return
+	}
+
+	s.SetCachedDraw(&doodad.CachedDraw{
+		Image: background,
+	})
 }
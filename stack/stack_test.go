@@ -0,0 +1,121 @@
+package stack
+
+import (
+	"testing"
+
+	"github.com/jhuggett/thingamabob/config"
+	"github.com/jhuggett/thingamabob/doodad"
+	"github.com/jhuggett/thingamabob/doodad/doodadtest"
+	"github.com/jhuggett/thingamabob/position/box"
+	"github.com/stretchr/testify/assert"
+)
+
+func rectsOf(s *Stack) []box.Rect {
+	rects := []box.Rect{}
+	for _, child := range s.Children().Doodads {
+		rects = append(rects, child.Layout().Rect())
+	}
+	return rects
+}
+
+func TestFitContentsSizing(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected box.Rect
+		children []box.Rect
+	}{
+		{
+			"left to right",
+			Config{Flow: config.LeftToRight, SpaceBetween: 5, Padding: config.EqualPadding(10)},
+			box.Rect{Width: 105, Height: 60},
+			[]box.Rect{{X: 10, Y: 10, Width: 50, Height: 20}, {X: 65, Y: 10, Width: 30, Height: 40}},
+		},
+		{
+			"top to bottom",
+			Config{Flow: config.TopToBottom, SpaceBetween: 5, Padding: config.EqualPadding(10)},
+			box.Rect{Width: 70, Height: 85},
+			[]box.Rect{{X: 10, Y: 10, Width: 50, Height: 20}, {X: 10, Y: 35, Width: 30, Height: 40}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := doodadtest.NewRoot(800, 600)
+			s := New(tt.config)
+			r.AddChild(s)
+			s.AddChild(doodadtest.Fixed(50, 20), doodadtest.Fixed(30, 40))
+
+			doodad.Setup(s)
+			r.Layout().Recalculate()
+
+			assert.Equal(t, tt.expected, s.Layout().Rect())
+			assert.Equal(t, tt.children, rectsOf(s))
+		})
+	}
+}
+
+func TestFillSizing(t *testing.T) {
+	t.Run("fills the space it is given", func(t *testing.T) {
+		r := doodadtest.NewRoot(300, 200)
+		s := New(Config{Flow: config.LeftToRight, LayoutRule: Fill, Justification: config.JustificationEnd})
+		r.AddChild(s)
+		s.AddChild(doodadtest.Fixed(50, 20))
+
+		doodad.Setup(s)
+		r.Layout().Recalculate()
+
+		assert.Equal(t, box.Rect{Width: 300, Height: 200}, s.Layout().Rect())
+		assert.Equal(t, []box.Rect{{X: 250, Width: 50, Height: 20}}, rectsOf(s))
+	})
+
+	t.Run("measures children in its final size", func(t *testing.T) {
+		r := doodadtest.NewRoot(800, 600)
+		outer := New(Config{
+			Flow:              config.LeftToRight,
+			LayoutRule:        Fill,
+			Padding:           config.EqualPadding(10),
+			VerticalAlignment: config.VerticalAlignmentBottom,
+			Justification:     config.JustificationEnd,
+		})
+		r.AddChild(outer)
+		// A filling stack measures as big as it is allowed, so it is only
+		// placed inside the padding if it was offered the right space.
+		inner := New(Config{LayoutRule: Fill})
+		outer.AddChild(inner)
+
+		doodad.Setup(outer)
+
+		// Sized by a step that runs after the stack's own, the way
+		// containers size their children.
+		outer.Layout().Computed(func(b *box.Box) {
+			b.SetDimensions(200, 100)
+		})
+		r.Layout().Recalculate()
+
+		assert.Equal(t, box.Rect{Width: 200, Height: 100}, outer.Layout().Rect())
+		assert.Equal(t, 10, inner.Layout().X())
+		assert.Equal(t, 10, inner.Layout().Y())
+
+		r.Layout().SetDimensions(400, 300)
+		r.Layout().Recalculate()
+		assert.Equal(t, 10, inner.Layout().X())
+		assert.Equal(t, 10, inner.Layout().Y())
+	})
+
+	t.Run("measure and arrange", func(t *testing.T) {
+		r := doodadtest.NewRoot(800, 600)
+		s := New(Config{Flow: config.LeftToRight, LayoutRule: Fill, SpaceBetween: 10})
+		r.AddChild(s)
+		s.AddChild(doodadtest.Fixed(50, 20), doodadtest.Fixed(30, 40))
+		doodad.Setup(s)
+
+		assert.Equal(t, doodad.Rectangle{Width: 90, Height: 40}, s.Measure(doodad.UnboundedConstraints()))
+		assert.Equal(t, doodad.Rectangle{Width: 120, Height: 70}, s.Measure(doodad.Loose(120, 70)))
+
+		doodad.Arrange(s, box.Rect{X: 5, Y: 5, Width: 120, Height: 70})
+		r.Layout().Recalculate()
+		assert.Equal(t, box.Rect{X: 5, Y: 5, Width: 120, Height: 70}, s.Layout().Rect())
+		assert.Equal(t, []box.Rect{{X: 5, Y: 5, Width: 50, Height: 20}, {X: 65, Y: 5, Width: 30, Height: 40}}, rectsOf(s))
+	})
+}